@@ -2,6 +2,9 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
+	"strconv"
+	"strings"
 
 	"github.com/guruorgoru/goru-verbal-interpreter/token"
 )
@@ -9,6 +12,8 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // first character of the node
+	End() token.Position // first character immediately after the node
 }
 
 type Statement interface {
@@ -23,6 +28,23 @@ type Expression interface {
 
 type Program struct {
 	Statements []Statement
+	Comments   []*CommentGroup `ast:"omitempty"` // only filled in when the lexer keeps comments
+
+	docs map[Statement]*CommentGroup
+}
+
+// Doc returns the comments written on the lines just before statement, or
+// nil if there are none.
+func (p *Program) Doc(statement Statement) *CommentGroup {
+	return p.docs[statement]
+}
+
+// SetDoc attaches doc to statement.
+func (p *Program) SetDoc(statement Statement, doc *CommentGroup) {
+	if p.docs == nil {
+		p.docs = map[Statement]*CommentGroup{}
+	}
+	p.docs[statement] = doc
 }
 
 func (p *Program) TokenLiteral() string {
@@ -33,6 +55,20 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
+func (p *Program) End() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -53,6 +89,17 @@ type LetStatement struct {
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() token.Position  { return ls.Token.Pos }
+
+func (ls *LetStatement) End() token.Position {
+	if ls.Value != nil {
+		return ls.Value.End()
+	}
+	if ls.Name != nil {
+		return ls.Name.End()
+	}
+	return ls.Token.End
+}
 
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
@@ -79,6 +126,8 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Position  { return i.Token.Pos }
+func (i *Identifier) End() token.Position  { return i.Token.End }
 
 func (i *Identifier) String() string {
 	return i.Value
@@ -93,6 +142,14 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position  { return rs.Token.Pos }
+
+func (rs *ReturnStatement) End() token.Position {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+	return rs.Token.End
+}
 
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
@@ -117,6 +174,20 @@ type ExpressionStatement struct {
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
 
+func (es *ExpressionStatement) Pos() token.Position {
+	if es.Expression != nil {
+		return es.Expression.Pos()
+	}
+	return es.Token.Pos
+}
+
+func (es *ExpressionStatement) End() token.Position {
+	if es.Expression != nil {
+		return es.Expression.End()
+	}
+	return es.Token.End
+}
+
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -124,6 +195,50 @@ func (es *ExpressionStatement) String() string {
 	return ""
 }
 
+// // note or /* note */
+
+type Comment struct {
+	Token token.Token
+}
+
+func (c *Comment) TokenLiteral() string { return c.Token.Literal }
+func (c *Comment) Pos() token.Position  { return c.Token.Pos }
+func (c *Comment) End() token.Position  { return c.Token.End }
+func (c *Comment) String() string       { return c.Token.Literal }
+
+// CommentGroup is a run of comments with no tokens between them.
+
+type CommentGroup struct {
+	List []*Comment
+}
+
+func (g *CommentGroup) TokenLiteral() string { return g.List[0].TokenLiteral() }
+func (g *CommentGroup) Pos() token.Position  { return g.List[0].Pos() }
+func (g *CommentGroup) End() token.Position  { return g.List[len(g.List)-1].End() }
+
+func (g *CommentGroup) String() string {
+	lines := []string{}
+	for _, comment := range g.List {
+		lines = append(lines, comment.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Text returns the comments without their // and /* */ delimiters.
+func (g *CommentGroup) Text() string {
+	lines := []string{}
+	for _, comment := range g.List {
+		text := comment.Token.Literal
+		if strings.HasPrefix(text, "/*") {
+			text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		} else {
+			text = strings.TrimPrefix(text, "//")
+		}
+		lines = append(lines, strings.TrimSpace(text))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // 69
 
 type IntegerLiteral struct {
@@ -133,11 +248,61 @@ type IntegerLiteral struct {
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Position  { return il.Token.Pos }
+func (il *IntegerLiteral) End() token.Position  { return il.Token.End }
 
 func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
 
+// 92233720368547758070, too big for IntegerLiteral
+
+type BigIntegerLiteral struct {
+	Token token.Token
+	Value *big.Int
+}
+
+func (bl *BigIntegerLiteral) expressionNode()      {}
+func (bl *BigIntegerLiteral) TokenLiteral() string { return bl.Token.Literal }
+func (bl *BigIntegerLiteral) Pos() token.Position  { return bl.Token.Pos }
+func (bl *BigIntegerLiteral) End() token.Position  { return bl.Token.End }
+
+func (bl *BigIntegerLiteral) String() string {
+	return bl.Token.Literal
+}
+
+// 3.14
+
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Position  { return fl.Token.Pos }
+func (fl *FloatLiteral) End() token.Position  { return fl.Token.End }
+
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
+// "hello"
+
+type StringLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Position  { return sl.Token.Pos }
+func (sl *StringLiteral) End() token.Position  { return sl.Token.End }
+
+func (sl *StringLiteral) String() string {
+	return strconv.Quote(sl.Value)
+}
+
 // !-
 
 type PrefixExpression struct {
@@ -151,6 +316,15 @@ func (pe *PrefixExpression) TokenLiteral() string {
 	return pe.Token.Literal
 }
 
+func (pe *PrefixExpression) Pos() token.Position { return pe.Token.Pos }
+
+func (pe *PrefixExpression) End() token.Position {
+	if pe.Right != nil {
+		return pe.Right.End()
+	}
+	return pe.Token.End
+}
+
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -174,6 +348,20 @@ type InfixExpression struct {
 func (ie *InfixExpression) expressionNode()      {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
 
+func (ie *InfixExpression) Pos() token.Position {
+	if ie.Left != nil {
+		return ie.Left.Pos()
+	}
+	return ie.Token.Pos
+}
+
+func (ie *InfixExpression) End() token.Position {
+	if ie.Right != nil {
+		return ie.Right.End()
+	}
+	return ie.Token.End
+}
+
 func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -195,6 +383,8 @@ type Boolean struct {
 
 func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Pos() token.Position  { return b.Token.Pos }
+func (b *Boolean) End() token.Position  { return b.Token.End }
 
 func (b *Boolean) String() string {
 	return b.Token.Literal
@@ -203,12 +393,15 @@ func (b *Boolean) String() string {
 // Block statement
 
 type BlockStatement struct {
-	Token      token.Token
+	Token      token.Token // the '{' token
 	Statements []Statement
+	RightBrace token.Token
 }
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BlockStatement) End() token.Position  { return bs.RightBrace.End }
 
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
@@ -223,12 +416,15 @@ func (bs *BlockStatement) String() string {
 // Block expression
 
 type BlockExpression struct {
-	Token      token.Token
+	Token      token.Token // the '{' token
 	Statements []Statement
+	RightBrace token.Token
 }
 
 func (be *BlockExpression) expressionNode()      {}
 func (be *BlockExpression) TokenLiteral() string { return be.Token.Literal }
+func (be *BlockExpression) Pos() token.Position  { return be.Token.Pos }
+func (be *BlockExpression) End() token.Position  { return be.RightBrace.End }
 
 func (be *BlockExpression) String() string {
 	var out bytes.Buffer
@@ -251,6 +447,17 @@ type IfStatement struct {
 
 func (is *IfStatement) statementNode()       {}
 func (is *IfStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *IfStatement) Pos() token.Position  { return is.Token.Pos }
+
+func (is *IfStatement) End() token.Position {
+	if is.Alternative != nil {
+		return is.Alternative.End()
+	}
+	if is.Consequence != nil {
+		return is.Consequence.End()
+	}
+	return is.Token.End
+}
 
 func (is *IfStatement) String() string {
 	var out bytes.Buffer
@@ -279,6 +486,17 @@ type IfExpression struct {
 
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Position  { return ie.Token.Pos }
+
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	if ie.Consequence != nil {
+		return ie.Consequence.End()
+	}
+	return ie.Token.End
+}
 
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
@@ -295,3 +513,382 @@ func (ie *IfExpression) String() string {
 
 	return out.String()
 }
+
+// karya(<parameters>) <block statement>
+
+type FunctionLiteral struct {
+	Token      token.Token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (fl *FunctionLiteral) expressionNode()      {}
+func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Position  { return fl.Token.Pos }
+
+func (fl *FunctionLiteral) End() token.Position {
+	if fl.Body != nil {
+		return fl.Body.End()
+	}
+	return fl.Token.End
+}
+
+func (fl *FunctionLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range fl.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(fl.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(fl.Body.String())
+
+	return out.String()
+}
+
+// <expression>(<comma separated expressions>)
+
+type CallExpression struct {
+	Token      token.Token // the '(' token
+	Function   Expression  // Identifier or FunctionLiteral
+	Arguments  []Expression
+	RightParen token.Token
+}
+
+func (ce *CallExpression) expressionNode()      {}
+func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position  { return ce.Function.Pos() }
+func (ce *CallExpression) End() token.Position  { return ce.RightParen.End }
+
+func (ce *CallExpression) String() string {
+	var out bytes.Buffer
+
+	args := []string{}
+	for _, a := range ce.Arguments {
+		args = append(args, a.String())
+	}
+
+	out.WriteString(ce.Function.String())
+	out.WriteString("(")
+	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// [<comma separated expressions>]
+
+type ArrayLiteral struct {
+	Token        token.Token // the '[' token
+	Elements     []Expression
+	RightBracket token.Token
+}
+
+func (al *ArrayLiteral) expressionNode()      {}
+func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position  { return al.Token.Pos }
+func (al *ArrayLiteral) End() token.Position  { return al.RightBracket.End }
+
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range al.Elements {
+		elements = append(elements, el.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// <expression>[<expression>]
+
+type IndexExpression struct {
+	Token        token.Token // the '[' token
+	Left         Expression
+	Index        Expression
+	RightBracket token.Token
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Position  { return ie.Left.Pos() }
+func (ie *IndexExpression) End() token.Position  { return ie.RightBracket.End }
+
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// <expression>[<low>:<high>], either bound may be left out
+
+type SliceExpression struct {
+	Token        token.Token // the '[' token
+	Left         Expression
+	Low          Expression
+	High         Expression
+	RightBracket token.Token
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) Pos() token.Position  { return se.Left.Pos() }
+func (se *SliceExpression) End() token.Position  { return se.RightBracket.End }
+
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Low != nil {
+		out.WriteString(se.Low.String())
+	}
+	out.WriteString(":")
+	if se.High != nil {
+		out.WriteString(se.High.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// {<key>: <value>, ...}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+type HashLiteral struct {
+	Token      token.Token // the '{' token
+	Pairs      []HashPair  // in source order
+	RightBrace token.Token
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Position  { return hl.Token.Pos }
+func (hl *HashLiteral) End() token.Position  { return hl.RightBrace.End }
+
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// <target> = <expression>
+
+type AssignExpression struct {
+	Token  token.Token // the '=' token
+	Target Expression
+	Value  Expression
+}
+
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) Pos() token.Position  { return ae.Target.Pos() }
+
+func (ae *AssignExpression) End() token.Position {
+	if ae.Value != nil {
+		return ae.Value.End()
+	}
+	return ae.Token.End
+}
+
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ae.Target.String())
+	out.WriteString(" " + ae.Token.Literal + " ")
+	out.WriteString(ae.Value.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// jabasamma (<condition>) <block statement>
+
+type WhileStatement struct {
+	Token     token.Token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) Pos() token.Position  { return ws.Token.Pos }
+
+func (ws *WhileStatement) End() token.Position {
+	if ws.Body != nil {
+		return ws.Body.End()
+	}
+	return ws.Token.End
+}
+
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ws.TokenLiteral())
+	out.WriteString(" (")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(") { ")
+	out.WriteString(ws.Body.String())
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// roka;
+
+type BreakStatement struct {
+	Token token.Token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BreakStatement) End() token.Position  { return bs.Token.End }
+func (bs *BreakStatement) String() string       { return bs.Token.Literal + ";" }
+
+// jari;
+
+type ContinueStatement struct {
+	Token token.Token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() token.Position  { return cs.Token.Pos }
+func (cs *ContinueStatement) End() token.Position  { return cs.Token.End }
+func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
+
+// pratyek <identifier> ma <expression> <block statement>
+
+type ForStatement struct {
+	Token    token.Token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Pos() token.Position  { return fs.Token.Pos }
+
+func (fs *ForStatement) End() token.Position {
+	if fs.Body != nil {
+		return fs.Body.End()
+	}
+	return fs.Token.End
+}
+
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(fs.TokenLiteral() + " ")
+	out.WriteString(fs.Variable.String())
+	out.WriteString(" ma ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(" { ")
+	out.WriteString(fs.Body.String())
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// fyak <expression>;
+
+type ThrowStatement struct {
+	Token token.Token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) Pos() token.Position  { return ts.Token.Pos }
+
+func (ts *ThrowStatement) End() token.Position {
+	if ts.Value != nil {
+		return ts.Value.End()
+	}
+	return ts.Token.End
+}
+
+func (ts *ThrowStatement) String() string {
+	return ts.TokenLiteral() + " " + ts.Value.String() + ";"
+}
+
+// prayas <block statement> samata (<identifier>) <block statement> antama <block statement>
+// where either the samata or the antama part may be left out
+
+type TryStatement struct {
+	Token      token.Token
+	Body       *BlockStatement
+	CatchParam *Identifier
+	Catch      *BlockStatement
+	Finally    *BlockStatement
+}
+
+func (ts *TryStatement) statementNode()       {}
+func (ts *TryStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TryStatement) Pos() token.Position  { return ts.Token.Pos }
+
+func (ts *TryStatement) End() token.Position {
+	switch {
+	case ts.Finally != nil:
+		return ts.Finally.End()
+	case ts.Catch != nil:
+		return ts.Catch.End()
+	case ts.Body != nil:
+		return ts.Body.End()
+	}
+	return ts.Token.End
+}
+
+func (ts *TryStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ts.TokenLiteral())
+	out.WriteString(" { ")
+	out.WriteString(ts.Body.String())
+	out.WriteString(" }")
+	if ts.Catch != nil {
+		out.WriteString(" samata (")
+		out.WriteString(ts.CatchParam.String())
+		out.WriteString(") { ")
+		out.WriteString(ts.Catch.String())
+		out.WriteString(" }")
+	}
+	if ts.Finally != nil {
+		out.WriteString(" antama { ")
+		out.WriteString(ts.Finally.String())
+		out.WriteString(" }")
+	}
+
+	return out.String()
+}
@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/guruorgoru/goru-verbal-interpreter/token"
@@ -27,3 +28,48 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. got=%q", program.String())
 	}
 }
+
+func TestFprint(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "manau", Pos: token.Position{Line: 1, Column: 1}},
+				Name: &Identifier{
+					Token: token.Token{Type: token.IDENTIFIER, Literal: "x", Pos: token.Position{Line: 1, Column: 7}},
+					Value: "x",
+				},
+				Value: &InfixExpression{
+					Token:    token.Token{Type: token.PLUS, Literal: "+", Pos: token.Position{Line: 1, Column: 13}},
+					Operator: "+",
+					Left: &IntegerLiteral{
+						Token: token.Token{Type: token.INT, Literal: "1", Pos: token.Position{Line: 1, Column: 11}},
+						Value: 1,
+					},
+					Right: &Identifier{
+						Token: token.Token{Type: token.IDENTIFIER, Literal: "y", Pos: token.Position{Line: 1, Column: 15}},
+						Value: "y",
+					},
+				},
+			},
+		},
+	}
+
+	expected := `Program (1:1)
+  Statements: [1]
+    0: LetStatement (1:1)
+      Name: Identifier "x" (1:7)
+      Value: InfixExpression (1:11)
+        Left: IntegerLiteral 1 (1:11)
+        Operator: "+"
+        Right: Identifier "y" (1:15)
+`
+
+	var out bytes.Buffer
+	if err := Fprint(&out, program); err != nil {
+		t.Fatalf("Fprint returned error: %v", err)
+	}
+
+	if out.String() != expected {
+		t.Errorf("Fprint output wrong.\nexpected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
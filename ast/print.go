@@ -0,0 +1,131 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/token"
+)
+
+var (
+	nodeType  = reflect.TypeOf((*Node)(nil)).Elem()
+	tokenType = reflect.TypeOf(token.Token{})
+)
+
+// Fprint writes node and everything below it to w as an indented tree, one
+// node per line followed by its position. Tokens are left out since the
+// interesting parts of them are already in the node's fields.
+func Fprint(w io.Writer, node Node) error {
+	p := &printer{w: w}
+	p.print("", reflect.ValueOf(node), 0)
+	return p.err
+}
+
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) line(depth int, format string, a ...any) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, strings.Repeat("  ", depth)+format+"\n", a...)
+}
+
+func (p *printer) print(label string, v reflect.Value, depth int) {
+	if label != "" {
+		label += ": "
+	}
+
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			p.line(depth, "%snil", label)
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			p.line(depth, "%snil", label)
+			return
+		}
+		name := v.Type().Elem().Name()
+		if leaf, ok := leafValue(v.Elem()); ok {
+			name += " " + leaf
+		}
+		if v.Type().Implements(nodeType) {
+			p.line(depth, "%s%s (%s)", label, name, v.Interface().(Node).Pos())
+		} else {
+			p.line(depth, "%s%s", label, name)
+		}
+		if _, ok := leafValue(v.Elem()); !ok {
+			p.fields(v.Elem(), depth+1)
+		}
+	case reflect.Struct:
+		p.line(depth, "%s%s", label, v.Type().Name())
+		p.fields(v, depth+1)
+	case reflect.Slice:
+		p.line(depth, "%s[%d]", label, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			p.print(fmt.Sprint(i), v.Index(i), depth+1)
+		}
+	case reflect.String:
+		p.line(depth, "%s%q", label, v.String())
+	default:
+		p.line(depth, "%s%v", label, v.Interface())
+	}
+}
+
+func (p *printer) fields(v reflect.Value, depth int) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Type == tokenType {
+			continue
+		}
+		// optional lists such as Program.Comments
+		if field.Tag.Get("ast") == "omitempty" && v.Field(i).Len() == 0 {
+			continue
+		}
+		p.print(field.Name, v.Field(i), depth)
+	}
+}
+
+// leafValue formats the single field of structs like Identifier or
+// IntegerLiteral, so they can be printed on one line.
+func leafValue(v reflect.Value) (string, bool) {
+	var value reflect.Value
+	count := 0
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Type == tokenType {
+			continue
+		}
+		value = v.Field(i)
+		count++
+	}
+
+	if count != 1 {
+		return "", false
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%q", value.String()), true
+	case reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return fmt.Sprint(value.Interface()), true
+	case reflect.Ptr:
+		// values like *big.Int that know how to print themselves
+		if stringer, ok := value.Interface().(fmt.Stringer); ok && !value.IsNil() && !value.Type().Implements(nodeType) {
+			return stringer.String(), true
+		}
+		return "", false
+	default:
+		return "", false
+	}
+}
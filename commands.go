@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/ast"
+	"github.com/guruorgoru/goru-verbal-interpreter/diag"
+	"github.com/guruorgoru/goru-verbal-interpreter/eval"
+	"github.com/guruorgoru/goru-verbal-interpreter/lexer"
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+	"github.com/guruorgoru/goru-verbal-interpreter/token"
+)
+
+// command is a REPL meta-command, typed as :name followed by its argument.
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(r *repl, arg string)
+}
+
+var commands = []command{
+	{"tokens", ":tokens <src>", "Show the tokens the lexer produces for src", (*repl).cmdTokens},
+	{"ast", ":ast <src>", "Show the syntax tree of src", (*repl).cmdAST},
+	{"env", ":env", "List the bindings in the session", (*repl).cmdEnv},
+	{"type", ":type <expr>", "Show the type of the value of expr", (*repl).cmdType},
+	{"load", ":load <file.goru>", "Run a file in the session", (*repl).cmdLoad},
+	{"save", ":save <file.goru>", "Save the inputs of the session to a file", (*repl).cmdSave},
+	{"reset", ":reset", "Forget every binding and start afresh", (*repl).cmdReset},
+	{"time", ":time <expr>", "Evaluate expr and show how long it took", (*repl).cmdTime},
+}
+
+func commandNames() []string {
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
+	}
+	return names
+}
+
+func (r *repl) runCommand(input string) {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(input, ":"), " ")
+	arg = strings.TrimSpace(arg)
+
+	if cmd, ok := findCommand(name); ok {
+		cmd.run(r, arg)
+		return
+	}
+
+	fmt.Printf("unknown command :%s, type help for the list\n", name)
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func (r *repl) cmdTokens(src string) {
+	lex := lexer.New(src)
+	lex.KeepComments(true)
+	for {
+		tok := lex.NextToken()
+		fmt.Printf("%-8s %-18s %q\n", tok.Pos, tok.Type, tok.Literal)
+		if tok.Type == token.EOF {
+			return
+		}
+	}
+}
+
+func (r *repl) cmdAST(src string) {
+	program, ok := r.parseInput("", src, true)
+	if !ok || program == nil {
+		return
+	}
+
+	if err := ast.Fprint(os.Stdout, program); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func (r *repl) cmdEnv(string) {
+	names := r.env.Names()
+	if len(names) == 0 {
+		fmt.Println("no bindings")
+		return
+	}
+
+	for _, name := range names {
+		value, _ := r.env.Get(name)
+		fmt.Printf("%s: %s = %s\n", name, value.Type(), value.Inspect())
+	}
+}
+
+func (r *repl) cmdType(src string) {
+	program, ok := r.parseInput("", src, true)
+	if !ok || program == nil {
+		return
+	}
+
+	evaluated := eval.Eval(program, r.env)
+	if errObj, isErr := evaluated.(*object.Error); isErr {
+		printDiagnostic(r.sources, diag.FromRuntimeError(errObj, scopeNames(r.env)))
+		return
+	}
+	if evaluated == nil {
+		evaluated = eval.NULL
+	}
+
+	fmt.Println(evaluated.Type())
+}
+
+func (r *repl) cmdLoad(filename string) {
+	if filename == "" {
+		fmt.Println("usage: :load <file.goru>")
+		return
+	}
+
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	program, ok := r.parseInput(filename, string(source), true)
+	if !ok || program == nil {
+		return
+	}
+
+	r.evalAndPrint(program, string(source))
+}
+
+func (r *repl) cmdSave(filename string) {
+	if filename == "" {
+		fmt.Println("usage: :save <file.goru>")
+		return
+	}
+
+	var out strings.Builder
+	for _, source := range r.session {
+		out.WriteString(source)
+		if !strings.HasSuffix(source, "\n") {
+			out.WriteString("\n")
+		}
+	}
+
+	if err := os.WriteFile(filename, []byte(out.String()), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	fmt.Printf("saved %d inputs to %s\n", len(r.session), filename)
+}
+
+func (r *repl) cmdReset(string) {
+	r.env = newEnvironment()
+	r.session = nil
+	fmt.Println("session reset")
+}
+
+func (r *repl) cmdTime(src string) {
+	program, ok := r.parseInput("", src, true)
+	if !ok || program == nil {
+		return
+	}
+
+	start := time.Now()
+	r.evalAndPrint(program, src)
+	fmt.Printf("took %s\n", time.Since(start))
+}
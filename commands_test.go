@@ -0,0 +1,111 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+)
+
+// captureStdout returns what f prints to stdout.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	return capture(t, &os.Stdout, f)
+}
+
+// captureStderr returns what f prints to stderr.
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	return capture(t, &os.Stderr, f)
+}
+
+func capture(t *testing.T, file **os.File, f func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := *file
+	*file = w
+	defer func() { *file = saved }()
+
+	f()
+	w.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestFindCommand(t *testing.T) {
+	for _, name := range commandNames() {
+		cmd, ok := findCommand(name)
+		if !ok || cmd.name != name {
+			t.Errorf("findCommand(%q) wrong. got=%q, %t", name, cmd.name, ok)
+		}
+	}
+
+	for _, name := range []string{"", "lo", "LOAD", "help"} {
+		if _, ok := findCommand(name); ok {
+			t.Errorf("findCommand(%q) found a command", name)
+		}
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	r := &repl{env: object.NewEnvironment(), sources: map[string]string{}}
+	path := filepath.Join(t.TempDir(), "session.goru")
+
+	out := captureStdout(t, func() { r.runCommand(":nope") })
+	if out != "unknown command :nope, type help for the list\n" {
+		t.Errorf("wrong output for an unknown command. got=%q", out)
+	}
+
+	// the argument is trimmed before it reaches the command
+	if err := os.WriteFile(path, []byte("manau x = 41;\nmanau y = x + 1;\ny\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out = captureStdout(t, func() { r.runCommand(":load   " + path + "  ") })
+	if out != "42\n" {
+		t.Errorf("wrong output for :load. got=%q", out)
+	}
+	if y, ok := r.env.Get("y"); !ok || y.Inspect() != "42" {
+		t.Errorf(":load did not bind y. got=%v", y)
+	}
+
+	out = captureStdout(t, func() { r.runCommand(":env") })
+	if out != "x: INTEGER = 41\ny: INTEGER = 42\n" {
+		t.Errorf("wrong output for :env. got=%q", out)
+	}
+
+	out = captureStdout(t, func() { r.runCommand(":type y * 2") })
+	if out != "INTEGER\n" {
+		t.Errorf("wrong output for :type. got=%q", out)
+	}
+
+	saved := filepath.Join(t.TempDir(), "saved.goru")
+	captureStdout(t, func() { r.runCommand(":save " + saved) })
+	source, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(source), "manau y = x + 1;") {
+		t.Errorf(":save did not write the session. got=%q", source)
+	}
+
+	out = captureStdout(t, func() { r.runCommand(":reset") })
+	if out != "session reset\n" || len(r.env.Names()) != 0 || r.session != nil {
+		t.Errorf(":reset left bindings behind. output=%q, names=%v", out, r.env.Names())
+	}
+
+	out = captureStdout(t, func() { r.runCommand(":load") })
+	if out != "usage: :load <file.goru>\n" {
+		t.Errorf("wrong output for :load without a file. got=%q", out)
+	}
+}
@@ -0,0 +1,158 @@
+// Package diag renders parser and runtime errors the way compilers do: a
+// file:line:column header, the offending source line with the bad part
+// underlined, and any notes or suggestions below it.
+package diag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+	"github.com/guruorgoru/goru-verbal-interpreter/parser"
+	"github.com/guruorgoru/goru-verbal-interpreter/token"
+)
+
+// Diagnostic is one error, ready to be shown to the user.
+type Diagnostic struct {
+	Pos     token.Position
+	End     token.Position // just past the bad part, a single caret is drawn when not after Pos
+	Message string
+	Notes   []string
+	Help    string         // a suggestion such as "did you mean `manau`?"
+	Stack   []object.Frame // the calls that led to the error, innermost first
+}
+
+// FromParserError describes a syntax error, underlining the token the parser
+// did not expect.
+func FromParserError(err *parser.Error) *Diagnostic {
+	d := &Diagnostic{Pos: err.Pos, Message: err.Message}
+	if err.Got.Pos == err.Pos {
+		d.End = err.Got.End
+	}
+
+	switch {
+	case err.Message == "unterminated comment":
+		d.Notes = append(d.Notes, "block comments nest, so every /* needs its own */")
+	case err.Got.Type == token.IDENTIFIER:
+		// a misspelt keyword reads as an identifier
+		if keyword, ok := Suggest(err.Got.Literal, keywordsLike(err.Got.Literal)); ok {
+			d.Help = fmt.Sprintf("did you mean `%s`?", keyword)
+		}
+	}
+
+	return d
+}
+
+// FromRuntimeError describes an error raised by the evaluator. Names are the
+// identifiers in scope, used to suggest a fix for a misspelt one.
+func FromRuntimeError(err *object.Error, names []string) *Diagnostic {
+	d := &Diagnostic{Pos: err.Pos, End: err.End, Message: err.Message, Stack: err.Stack}
+
+	for _, prefix := range []string{"identifier not found: ", "cannot assign to undeclared identifier: "} {
+		name, found := strings.CutPrefix(err.Message, prefix)
+		if !found {
+			continue
+		}
+		candidates := append(append([]string{}, names...), keywordsLike(name)...)
+		if suggestion, ok := Suggest(name, candidates); ok {
+			d.Help = fmt.Sprintf("did you mean `%s`?", suggestion)
+		}
+	}
+
+	return d
+}
+
+// keywordsLike returns the keywords written in the same script as name, so
+// that a misspelt Latin name is not offered a Devanagari keyword.
+func keywordsLike(name string) []string {
+	var keywords []string
+	for _, keyword := range token.Keywords() {
+		if isDevanagari(keyword) == isDevanagari(name) {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// isDevanagari reports whether the first letter of s is Devanagari.
+func isDevanagari(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return unicode.Is(unicode.Devanagari, r)
+		}
+	}
+	return false
+}
+
+// Suggest returns the candidate closest to name by edit distance, if one is
+// close enough to be a likely typo. Names of a single letter get no
+// suggestion, as every other short name is within reach of them.
+func Suggest(name string, candidates []string) (string, bool) {
+	length := len([]rune(name))
+	limit := min(max(1, length/3), length-1)
+	best, bestDistance := "", limit+1
+
+	sorted := append([]string{}, candidates...)
+	sort.Strings(sorted)
+	for _, candidate := range sorted {
+		if candidate == name {
+			continue
+		}
+		if d := distance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best, best != ""
+}
+
+// distance is the edit distance between a and b, counted in runes, where
+// swapping two neighbours counts as one edit like any other typo.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+// UseColor reports whether diagnostics written to w should be coloured: only
+// when w is a terminal and the user has not asked for plain output.
+func UseColor(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	if _, set := os.LookupEnv("NO_COLOR"); set || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
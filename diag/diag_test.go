@@ -0,0 +1,296 @@
+package diag
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/eval"
+	"github.com/guruorgoru/goru-verbal-interpreter/lexer"
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+	"github.com/guruorgoru/goru-verbal-interpreter/parser"
+	"github.com/guruorgoru/goru-verbal-interpreter/token"
+)
+
+func TestParserErrorDiagnostic(t *testing.T) {
+	input := "manau x = 5;\nmanau y = (1 + ;\n"
+
+	p := parser.New(lexer.NewFile("test.goru", input))
+	p.ParseProgram()
+	if len(p.Errors()) != 1 {
+		t.Fatalf("expected 1 parser error, got %d", len(p.Errors()))
+	}
+
+	expected := "test.goru:2:16: error: no prefix parse function for ;\n" +
+		"  |\n" +
+		"2 | manau y = (1 + ;\n" +
+		"  |                ^\n"
+
+	printer := &Printer{Sources: map[string]string{"test.goru": input}}
+	var out bytes.Buffer
+	if err := printer.Fprint(&out, FromParserError(p.Errors()[0])); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("wrong diagnostic.\nexpected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestRuntimeErrorDiagnostic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"manau x = 5;\nmanau y = x + satya;",
+			"2:11: error: type mismatch: INTEGER + BOOLEAN\n" +
+				"  |\n" +
+				"2 | manau y = x + satya;\n" +
+				"  |           ^^^^^^^^^\n",
+		},
+		{
+			"manau count = 1;\n\tcount + conut;",
+			"2:10: error: identifier not found: conut\n" +
+				"  |\n" +
+				"2 |     count + conut;\n" +
+				"  |             ^^^^^\n" +
+				"  = help: did you mean `count`?\n",
+		},
+		{
+			"manaw x = 5;",
+			"1:1: error: identifier not found: manaw\n" +
+				"  |\n" +
+				"1 | manaw x = 5;\n" +
+				"  | ^^^^^\n" +
+				"  = help: did you mean `manau`?\n",
+		},
+		{
+			"मानौ नाम = 1;\nनम = 2;",
+			"2:1: error: cannot assign to undeclared identifier: नम\n" +
+				"  |\n" +
+				"2 | नम = 2;\n" +
+				"  | ^^^^^^\n" +
+				"  = help: did you mean `नाम`?\n",
+		},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors for %q: %v", tt.input, p.Errors())
+		}
+
+		env := object.NewEnvironment()
+		errObj, ok := eval.Eval(program, env).(*object.Error)
+		if !ok {
+			t.Fatalf("no error for %q", tt.input)
+		}
+
+		printer := &Printer{Sources: map[string]string{"": tt.input}}
+		var out bytes.Buffer
+		printer.Fprint(&out, FromRuntimeError(errObj, env.Names()))
+		if out.String() != tt.expected {
+			t.Errorf("wrong diagnostic for %q.\nexpected:\n%s\ngot:\n%s", tt.input, tt.expected, out.String())
+		}
+	}
+}
+
+func TestDiagnosticWithoutPosition(t *testing.T) {
+	d := &Diagnostic{Message: "something broke", Notes: []string{"it really did"}}
+
+	printer := &Printer{Sources: map[string]string{"": "1 + 1"}}
+	var out bytes.Buffer
+	printer.Fprint(&out, d)
+
+	expected := "error: something broke\n = note: it really did\n"
+	if out.String() != expected {
+		t.Errorf("wrong diagnostic. expected=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestDiagnosticInAnotherSource(t *testing.T) {
+	lib := "manau f = karya(x) { x + satya };"
+	env := object.NewEnvironment()
+	eval.Eval(parser.New(lexer.NewFile("lib.goru", lib)).ParseProgram(), env)
+
+	input := "f(1)"
+	errObj, ok := eval.Eval(parser.New(lexer.NewFile("main.goru", input)).ParseProgram(), env).(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned")
+	}
+
+	// the snippet comes from the file the error is in, not the one being run
+	expected := "lib.goru:1:22: error: type mismatch: INTEGER + BOOLEAN\n" +
+		"  |\n" +
+		"1 | manau f = karya(x) { x + satya };\n" +
+		"  |                      ^^^^^^^^^\n" +
+		"  = trace, most recent call first:\n" +
+		"      at f (main.goru:1:1)\n"
+
+	printer := &Printer{Sources: map[string]string{"lib.goru": lib, "main.goru": input}}
+	var out bytes.Buffer
+	printer.Fprint(&out, FromRuntimeError(errObj, nil))
+	if out.String() != expected {
+		t.Errorf("wrong diagnostic.\nexpected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	// without the source of that file there is no snippet to show
+	printer = &Printer{Sources: map[string]string{"main.goru": input}}
+	out.Reset()
+	printer.Fprint(&out, FromRuntimeError(errObj, nil))
+	expected = "lib.goru:1:22: error: type mismatch: INTEGER + BOOLEAN\n" +
+		" = trace, most recent call first:\n" +
+		"     at f (main.goru:1:1)\n"
+	if out.String() != expected {
+		t.Errorf("wrong diagnostic without the source.\nexpected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestColor(t *testing.T) {
+	p := parser.New(lexer.New("manau = 1;"))
+	p.ParseProgram()
+
+	plain := &Printer{Sources: map[string]string{"": "manau = 1;"}}
+	colored := &Printer{Sources: map[string]string{"": "manau = 1;"}, Color: true}
+
+	var plainOut, coloredOut bytes.Buffer
+	plain.Fprint(&plainOut, FromParserError(p.Errors()[0]))
+	colored.Fprint(&coloredOut, FromParserError(p.Errors()[0]))
+
+	if strings.Contains(plainOut.String(), "\x1b[") {
+		t.Errorf("plain output has escape codes: %q", plainOut.String())
+	}
+	if !strings.Contains(coloredOut.String(), "\x1b[") {
+		t.Errorf("colored output has no escape codes: %q", coloredOut.String())
+	}
+	if UseColor(&plainOut) {
+		t.Errorf("UseColor is true for a buffer")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"manau", "karya", "len", "chhap", "count"}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"manaw", "manau"},
+		{"krya", "karya"},
+		{"lne", "len"},
+		{"conut", "count"},
+		{"chhapp", "chhap"},
+		{"x", ""},
+		{"zzzzz", ""},
+		{"manau", ""},
+		// one letter is too little to go on
+		{"b", ""},
+		{"i", ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := Suggest(tt.name, append(candidates, "a", "y"))
+		if got != tt.expected || ok != (tt.expected != "") {
+			t.Errorf("Suggest(%q) wrong. expected=%q, got=%q (%t)", tt.name, tt.expected, got, ok)
+		}
+	}
+}
+
+func TestKeywordSuggestionsKeepToTheScript(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// र is a keyword one edit away from any single letter
+		{"x", ""},
+		{"i + 1", ""},
+		{"manaw x = 1", "did you mean `manau`?"},
+		{"मनौ", "did you mean `मानौ`?"},
+		{"वाा", "did you mean `वा`?"},
+		{"क", ""},
+		// Latin names are only offered Latin keywords, and the other way round
+		{"ra1", "did you mean `ra`?"},
+		{"र१", "did you mean `र`?"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := eval.Eval(parser.New(lexer.New(tt.input)).ParseProgram(), object.NewEnvironment()).(*object.Error)
+		if !ok {
+			t.Fatalf("no error for %q", tt.input)
+		}
+
+		d := FromRuntimeError(errObj, nil)
+		if d.Help != tt.expected {
+			t.Errorf("wrong help for %q. expected=%q, got=%q", tt.input, tt.expected, d.Help)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"ab", "ba", 1},
+		{"नाम", "नम", 1},
+	}
+
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("distance(%q, %q) wrong. expected=%d, got=%d", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	input := "manau f = karya(n) { yadi (n == 0) { 1 + jhuth } natra { f(n - 1) } };\nf(3);"
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	errObj, ok := eval.Eval(program, object.NewEnvironment()).(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned")
+	}
+
+	expected := "1:38: error: type mismatch: INTEGER + BOOLEAN\n" +
+		"  |\n" +
+		"1 | manau f = karya(n) { yadi (n == 0) { 1 + jhuth } natra { f(n - 1) } };\n" +
+		"  |                                      ^^^^^^^^^\n" +
+		"  = trace, most recent call first:\n" +
+		"      at f (1:58)\n" +
+		"      ... called 2 more times from there\n" +
+		"      at f (2:1)\n"
+
+	printer := &Printer{Sources: map[string]string{"": input}}
+	var out bytes.Buffer
+	printer.Fprint(&out, FromRuntimeError(errObj, nil))
+	if out.String() != expected {
+		t.Errorf("wrong diagnostic.\nexpected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestLongTraceIsElided(t *testing.T) {
+	var stack []object.Frame
+	for i := 1; i <= 40; i++ {
+		name := "even"
+		if i%2 == 0 {
+			name = "odd"
+		}
+		stack = append(stack, object.Frame{Function: name, Pos: token.Position{Line: i, Column: 1}})
+	}
+
+	lines := traceLines(stack)
+	if len(lines) != maxTraceLines+1 {
+		t.Fatalf("wrong number of lines. expected=%d, got=%d: %q", maxTraceLines+1, len(lines), lines)
+	}
+	if lines[0] != "at even (1:1)" || lines[len(lines)-1] != "at odd (40:1)" {
+		t.Errorf("innermost or outermost call missing: %q", lines)
+	}
+	if lines[maxTraceLines/2] != "... 24 more calls ..." {
+		t.Errorf("wrong elision line. got=%q", lines[maxTraceLines/2])
+	}
+}
@@ -0,0 +1,160 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+	"github.com/guruorgoru/goru-verbal-interpreter/token"
+)
+
+const (
+	bold  = "\x1b[1m"
+	red   = "\x1b[1;31m"
+	blue  = "\x1b[1;34m"
+	cyan  = "\x1b[1;36m"
+	green = "\x1b[1;32m"
+	reset = "\x1b[0m"
+
+	tabWidth = 4
+
+	// traces longer than this show only their innermost and outermost
+	// calls, and runs of the same call are folded into one line
+	maxTraceLines = 16
+)
+
+// Printer writes diagnostics about the source texts it knows of.
+type Printer struct {
+	Sources map[string]string // source text by the file name positions carry
+	Color   bool              // use ANSI colours, see UseColor
+}
+
+// Fprint writes d to w, with the source line it points at when the printer
+// has the source of that file.
+func (p *Printer) Fprint(w io.Writer, d *Diagnostic) error {
+	var out strings.Builder
+
+	if d.Pos.IsValid() {
+		out.WriteString(p.paint(bold, d.Pos.String()+": "))
+	}
+	out.WriteString(p.paint(red, "error") + p.paint(bold, ": "+d.Message) + "\n")
+
+	gutter := ""
+	if line, ok := p.line(d.Pos); ok {
+		number := strconv.Itoa(d.Pos.Line)
+		gutter = strings.Repeat(" ", len(number))
+
+		out.WriteString(p.paint(blue, gutter+" |") + "\n")
+		out.WriteString(p.paint(blue, number+" |") + " " + expandTabs(line) + "\n")
+
+		start, width := p.span(line, d)
+		out.WriteString(p.paint(blue, gutter+" |") + " " + strings.Repeat(" ", start) + p.paint(red, strings.Repeat("^", width)) + "\n")
+	}
+
+	for _, note := range d.Notes {
+		out.WriteString(gutter + " = " + p.paint(cyan, "note") + ": " + note + "\n")
+	}
+	if d.Help != "" {
+		out.WriteString(gutter + " = " + p.paint(green, "help") + ": " + d.Help + "\n")
+	}
+	if len(d.Stack) > 0 {
+		out.WriteString(gutter + " = " + p.paint(cyan, "trace") + ", most recent call first:\n")
+		for _, line := range traceLines(d.Stack) {
+			out.WriteString(gutter + "     " + line + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// traceLines formats stack one call per line, folding repeats of the same
+// call, as in recursion, and eliding the middle of a trace that is too long.
+func traceLines(stack []object.Frame) []string {
+	var lines []string
+	var calls []int // how many frames each line stands for
+
+	for i := 0; i < len(stack); {
+		frame := stack[i]
+		run := 1
+		for i+run < len(stack) && stack[i+run] == frame {
+			run++
+		}
+		lines = append(lines, "at "+frame.Function+" ("+frame.Pos.String()+")")
+		calls = append(calls, 1)
+		if run > 1 {
+			lines = append(lines, fmt.Sprintf("... called %d more times from there", run-1))
+			calls = append(calls, run-1)
+		}
+		i += run
+	}
+
+	if len(lines) <= maxTraceLines {
+		return lines
+	}
+
+	half := maxTraceLines / 2
+	elided := 0
+	for _, n := range calls[half : len(calls)-half] {
+		elided += n
+	}
+
+	short := append([]string{}, lines[:half]...)
+	short = append(short, fmt.Sprintf("... %d more calls ...", elided))
+	return append(short, lines[len(lines)-half:]...)
+}
+
+// line returns the text of the source line pos is on.
+func (p *Printer) line(pos token.Position) (string, bool) {
+	source, ok := p.Sources[pos.Filename]
+	if !ok || !pos.IsValid() {
+		return "", false
+	}
+
+	lines := strings.Split(source, "\n")
+	if pos.Line > len(lines) {
+		return "", false
+	}
+	return strings.TrimSuffix(lines[pos.Line-1], "\r"), true
+}
+
+// span returns the display column where the underline starts and how wide it
+// is. Columns count runes, which need not be one cell each on screen.
+func (p *Printer) span(line string, d *Diagnostic) (int, int) {
+	runes := []rune(line)
+	from := min(max(d.Pos.Column-1, 0), len(runes))
+	to := min(from+1, len(runes))
+	if d.End.Line == d.Pos.Line && d.End.Column > d.Pos.Column {
+		to = min(d.End.Column-1, len(runes))
+	}
+
+	// past the end of the line, as at EOF, a lone caret is drawn there
+	return displayWidth(runes[:from]), max(displayWidth(runes[from:to]), 1)
+}
+
+func (p *Printer) paint(color, text string) string {
+	if !p.Color {
+		return text
+	}
+	return color + text + reset
+}
+
+func displayWidth(runes []rune) int {
+	width := 0
+	for _, r := range runes {
+		if r == '\t' {
+			width += tabWidth
+			continue
+		}
+		width += runewidth.RuneWidth(r)
+	}
+	return width
+}
+
+func expandTabs(line string) string {
+	return strings.ReplaceAll(line, "\t", strings.Repeat(" ", tabWidth))
+}
@@ -0,0 +1,116 @@
+package eval
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+)
+
+// Stdout is where chhap writes and Stdin is where padh reads, swap them to
+// redirect the I/O of scripts.
+var (
+	Stdout io.Writer     = os.Stdout
+	Stdin  *bufio.Reader = bufio.NewReader(os.Stdin)
+)
+
+var (
+	builtinsMu sync.RWMutex
+	builtins   = map[string]*object.Builtin{}
+)
+
+func init() {
+	RegisterBuiltin("len", builtinLen)
+	RegisterBuiltin("chhap", builtinPrint)
+	RegisterBuiltin("padh", builtinRead)
+}
+
+// RegisterBuiltin makes fn callable from scripts as name. Identifiers bound
+// in the environment shadow builtins, and registering an existing name
+// replaces it.
+func RegisterBuiltin(name string, fn object.BuiltinFunction) {
+	builtinsMu.Lock()
+	defer builtinsMu.Unlock()
+
+	builtins[name] = &object.Builtin{Name: name, Fn: fn}
+}
+
+func lookupBuiltin(name string) (*object.Builtin, bool) {
+	builtinsMu.RLock()
+	defer builtinsMu.RUnlock()
+
+	builtin, ok := builtins[name]
+	return builtin, ok
+}
+
+// BuiltinNames returns the names of all registered builtins, sorted.
+func BuiltinNames() []string {
+	builtinsMu.RLock()
+	defer builtinsMu.RUnlock()
+
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// len(x) counts the elements of an array, hash or range, or the characters
+// of a string.
+func builtinLen(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments to `len`: want=1, got=%d", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.String:
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+	case *object.Array:
+		return &object.Integer{Value: int64(len(arg.Elements))}
+	case *object.Hash:
+		return &object.Integer{Value: int64(arg.Len())}
+	case *object.Range:
+		// a count, not arithmetic, so exact even in checked mode
+		return bigInteger(arg.Len())
+	default:
+		return newError("argument to `len` not supported, got %s", args[0].Type())
+	}
+}
+
+// chhap(a, b, ...) prints its arguments separated by spaces.
+func builtinPrint(args ...object.Object) object.Object {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg.Inspect()
+	}
+
+	fmt.Fprintln(Stdout, strings.Join(parts, " "))
+
+	return NULL
+}
+
+// padh(prompt) prints the optional prompt and reads one line of input,
+// returning null once the input is exhausted.
+func builtinRead(args ...object.Object) object.Object {
+	if len(args) > 1 {
+		return newError("wrong number of arguments to `padh`: want=0 or 1, got=%d", len(args))
+	}
+
+	if len(args) == 1 {
+		fmt.Fprint(Stdout, args[0].Inspect())
+	}
+
+	line, err := Stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return NULL
+	}
+
+	return &object.String{Value: strings.TrimRight(line, "\r\n")}
+}
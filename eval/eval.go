@@ -2,17 +2,27 @@ package eval
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"strings"
 
 	"github.com/guruorgoru/goru-verbal-interpreter/ast"
 	"github.com/guruorgoru/goru-verbal-interpreter/object"
+	"github.com/guruorgoru/goru-verbal-interpreter/token"
 )
 
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	NULL     = &object.Null{}
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
+// MaxCallDepth bounds how deeply function calls may nest, so that runaway
+// recursion is reported as an error rather than crashing the interpreter.
+const MaxCallDepth = 10000
+
 func newError(format string, a ...any) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
@@ -24,7 +34,18 @@ func isError(obj object.Object) bool {
 	return false
 }
 
+// Eval evaluates node in env. Errors raised while evaluating the node are
+// stamped with the position of the innermost node that produced them.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	result := evalNode(node, env)
+	if err, ok := result.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+		err.End = node.End()
+	}
+	return result
+}
+
+func evalNode(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node, env)
@@ -32,65 +53,119 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalIfStatement(node, env)
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
+	case *ast.ThrowStatement:
+		value := Eval(node.Value, env)
+		if unwinds(value) {
+			return value
+		}
+		return &object.Error{Message: "uncaught exception: " + value.Inspect(), Thrown: value}
+	case *ast.TryStatement:
+		return evalTryStatement(node, env)
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 	case *ast.BlockExpression:
 		return evalBlockExpression(node, env)
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.BigIntegerLiteral:
+		return &object.BigInt{Value: node.Value}
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
 	case *ast.Boolean:
 		return inputBoolToBoolObj(node.Value)
 	case *ast.LetStatement:
 		val := Eval(node.Value, env)
-		if isError(val) {
+		if unwinds(val) {
 			return val
 		}
 		env.Set(node.Name.Value, val)
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if unwinds(right) {
 			return right
 		}
 		switch node.Operator {
 		case "!":
 			return evalBangOp(right)
 		case "-":
-			return evalNegateOp(right)
+			return evalNegateOp(right, env.CheckedArithmetic())
+		case "~":
+			return evalBitwiseNotOp(right)
 		default:
 			return newError("unknown operator: %s%s", node.Operator, right.Type())
 		}
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
+	case *ast.FunctionLiteral:
+		return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env}
+	case *ast.CallExpression:
+		function := Eval(node.Function, env)
+		if unwinds(function) {
+			return function
+		}
+		args := evalExpressions(node.Arguments, env)
+		if len(args) == 1 && unwinds(args[0]) {
+			return args[0]
+		}
+		result := applyFunction(function, args)
+		// errors from inside the body already carry a position, those
+		// about the call itself get the call's position from Eval
+		if err, ok := result.(*object.Error); ok && err.Pos.IsValid() {
+			err.Stack = append(err.Stack, object.Frame{Function: calleeName(node.Function), Pos: node.Pos()})
+		}
+		return result
 	case *ast.InfixExpression:
 		left := Eval(node.Left, env)
-		if isError(left) {
+		if unwinds(left) {
 			return left
 		}
+		if node.Token.Type == token.AND || node.Token.Type == token.OR {
+			return evalLogicalExpression(node, left, env)
+		}
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if unwinds(right) {
 			return right
 		}
-		if left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ {
-			return evalIntegerInfixOp(node.Operator, left, right)
+		return evalInfixOp(node.Operator, left, right, env.CheckedArithmetic())
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && unwinds(elements[0]) {
+			return elements[0]
 		}
-		if left.Type() != right.Type() {
-			return newError("type mismatch: %s %s %s", left.Type(), node.Operator, right.Type())
+		return &object.Array{Elements: elements}
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if unwinds(left) {
+			return left
 		}
-		switch node.Operator {
-		case "==":
-			return inputBoolToBoolObj(left == right)
-		case "!=":
-			return inputBoolToBoolObj(left != right)
-		default:
-			return newError("unknown operator: %s %s %s", left.Type(), node.Operator, right.Type())
+		index := Eval(node.Index, env)
+		if unwinds(index) {
+			return index
 		}
+		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
 	case *ast.ReturnStatement:
 		var val object.Object
 		if node.ReturnValue != nil {
 			val = Eval(node.ReturnValue, env)
-			if isError(val) {
+			if unwinds(val) {
 				return val
 			}
 		} else {
@@ -101,23 +176,132 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	return NULL
 }
 
-func evalIntegerInfixOp(op string, left, right object.Object) object.Object {
+// evalInfixOp applies op to left and right. With checked set, int64
+// overflow is an error instead of giving a BigInt.
+func evalInfixOp(op string, left, right object.Object, checked bool) object.Object {
+	if left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ {
+		return evalIntegerInfixOp(op, left, right, checked)
+	}
+	if isInteger(left) && isInteger(right) {
+		return evalBigIntInfixOp(op, left, right)
+	}
+	if isNumber(left) && isNumber(right) {
+		return evalFloatInfixOp(op, left, right)
+	}
+	if left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ {
+		return evalStringInfixOp(op, left, right)
+	}
+	if left.Type() != right.Type() {
+		return newError("type mismatch: %s %s %s", left.Type(), op, right.Type())
+	}
+	switch op {
+	case "==":
+		return inputBoolToBoolObj(left == right)
+	case "!=":
+		return inputBoolToBoolObj(left != right)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	}
+}
+
+// evalLogicalExpression only evaluates the right operand when the left one
+// does not already decide the result, and returns the deciding operand
+// itself rather than a boolean.
+func evalLogicalExpression(node *ast.InfixExpression, left object.Object, env *object.Environment) object.Object {
+	if node.Token.Type == token.AND && !isTruthy(left) {
+		return left
+	}
+	if node.Token.Type == token.OR && isTruthy(left) {
+		return left
+	}
+	return Eval(node.Right, env)
+}
+
+func evalIntegerInfixOp(op string, left, right object.Object, checked bool) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
 	switch op {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		value, exact := addInt(leftVal, rightVal)
+		return integerResult(value, exact, op, leftVal, rightVal, checked)
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		value, exact := subInt(leftVal, rightVal)
+		return integerResult(value, exact, op, leftVal, rightVal, checked)
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		value, exact := mulInt(leftVal, rightVal)
+		return integerResult(value, exact, op, leftVal, rightVal, checked)
 	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
+		if rightVal == 0 {
+			return newError("division by zero: %d / 0", leftVal)
+		}
+		// the one quotient that does not fit, -MinInt64
+		exact := leftVal != math.MinInt64 || rightVal != -1
+		return integerResult(leftVal/rightVal, exact, op, leftVal, rightVal, checked)
+	case "%":
+		if rightVal == 0 {
+			return newError("division by zero: %d %% 0", leftVal)
+		}
+		return &object.Integer{Value: leftVal % rightVal}
+	case "**":
+		if rightVal < 0 {
+			return newError("negative exponent: %d ** %d", leftVal, rightVal)
+		}
+		value, exact := powInt(leftVal, rightVal)
+		return integerResult(value, exact, op, leftVal, rightVal, checked)
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "<<":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		value, exact := shlInt(leftVal, rightVal)
+		return integerResult(value, exact, op, leftVal, rightVal, checked)
+	case ">>":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		return &object.Integer{Value: leftVal >> uint64(rightVal)}
+	case "<":
+		return inputBoolToBoolObj(leftVal < rightVal)
+	case ">":
+		return inputBoolToBoolObj(leftVal > rightVal)
+	case "<=":
+		return inputBoolToBoolObj(leftVal <= rightVal)
+	case ">=":
+		return inputBoolToBoolObj(leftVal >= rightVal)
+	case "==":
+		return inputBoolToBoolObj(leftVal == rightVal)
+	case "!=":
+		return inputBoolToBoolObj(leftVal != rightVal)
+	case "..":
+		return &object.Range{Start: leftVal, End: rightVal}
+	case "..=":
+		return &object.Range{Start: leftVal, End: rightVal, Inclusive: true}
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	}
+}
+
+func evalStringInfixOp(op string, left, right object.Object) object.Object {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+
+	switch op {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
 	case "<":
 		return inputBoolToBoolObj(leftVal < rightVal)
 	case ">":
 		return inputBoolToBoolObj(leftVal > rightVal)
+	case "<=":
+		return inputBoolToBoolObj(leftVal <= rightVal)
+	case ">=":
+		return inputBoolToBoolObj(leftVal >= rightVal)
 	case "==":
 		return inputBoolToBoolObj(leftVal == rightVal)
 	case "!=":
@@ -140,12 +324,35 @@ func evalBangOp(right object.Object) object.Object {
 	}
 }
 
-func evalNegateOp(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+func evalNegateOp(right object.Object, checked bool) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		value, exact := negInt(right.Value)
+		if exact {
+			return &object.Integer{Value: value}
+		}
+		if checked {
+			return newError("integer overflow: -(%d)", right.Value)
+		}
+		return bigInteger(new(big.Int).Neg(big.NewInt(right.Value)))
+	case *object.BigInt:
+		return bigInteger(new(big.Int).Neg(right.Value))
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+}
+
+func evalBitwiseNotOp(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^right.Value}
+	case *object.BigInt:
+		return bigInteger(new(big.Int).Not(right.Value))
+	default:
+		return newError("unknown operator: ~%s", right.Type())
+	}
 }
 
 func evalStmt(stmt []ast.Statement, env *object.Environment) object.Object {
@@ -164,7 +371,7 @@ func evalStmt(stmt []ast.Statement, env *object.Environment) object.Object {
 
 func evalIfStatement(ie *ast.IfStatement, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
-	if isError(condition) {
+	if unwinds(condition) {
 		return condition
 	}
 
@@ -179,7 +386,7 @@ func evalIfStatement(ie *ast.IfStatement, env *object.Environment) object.Object
 
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
-	if isError(condition) {
+	if unwinds(condition) {
 		return condition
 	}
 
@@ -221,6 +428,8 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break, *object.Continue:
+			return newError("%s outside of a loop", result.Inspect())
 		}
 	}
 	return result
@@ -231,8 +440,7 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
 		if result != nil {
-			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJECT || rt == object.ERROR_OBJ {
+			if unwinds(result) {
 				return result
 			}
 		}
@@ -245,8 +453,7 @@ func evalBlockExpression(block *ast.BlockExpression, env *object.Environment) ob
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
 		if result != nil {
-			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJECT || rt == object.ERROR_OBJ {
+			if unwinds(result) {
 				return result
 			}
 		}
@@ -254,11 +461,447 @@ func evalBlockExpression(block *ast.BlockExpression, env *object.Environment) ob
 	return result
 }
 
+// unwinds reports whether result stops the statements of a block from
+// running: returns, errors, and roka/jari on their way to the loop. A block
+// can be used as a value, so wherever a value is consumed these have to be
+// passed on rather than used.
+func unwinds(result object.Object) bool {
+	if result == nil {
+		return false
+	}
+	switch result.Type() {
+	case object.RETURN_VALUE_OBJECT, object.ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+		return true
+	default:
+		return false
+	}
+}
+
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(ws.Condition, env)
+		if unwinds(condition) {
+			return condition
+		}
+
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		if result, stop := evalLoopBody(ws.Body, env); stop {
+			return result
+		}
+	}
+}
+
+// evalTryStatement runs the prayas block and hands an error raised in it to
+// the samata block. The antama block runs last, whether the others finished,
+// returned, left a loop or raised an error, and only changes the outcome when
+// it unwinds itself.
+func evalTryStatement(ts *ast.TryStatement, env *object.Environment) object.Object {
+	result := Eval(ts.Body, env)
+
+	if err, ok := result.(*object.Error); ok && ts.Catch != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		catchEnv.Set(ts.CatchParam.Value, caughtValue(err))
+		result = Eval(ts.Catch, catchEnv)
+	}
+
+	if ts.Finally != nil {
+		if final := Eval(ts.Finally, env); final != nil && unwinds(final) {
+			return final
+		}
+	}
+
+	return result
+}
+
+// caughtValue is what a samata block gets to see of err: the value that was
+// thrown, or for an error raised by the interpreter a hash of its message
+// and where it happened.
+func caughtValue(err *object.Error) object.Object {
+	if err.Thrown != nil {
+		return err.Thrown
+	}
+
+	caught := object.NewHash()
+	set := func(key string, value object.Object) {
+		k := &object.String{Value: key}
+		caught.Set(k.HashKey(), object.HashPair{Key: k, Value: value})
+	}
+	set("message", &object.String{Value: err.Message})
+	set("file", &object.String{Value: err.Pos.Filename})
+	set("line", &object.Integer{Value: int64(err.Pos.Line)})
+	set("column", &object.Integer{Value: int64(err.Pos.Column)})
+
+	return caught
+}
+
+// evalLoopBody runs one round of a loop and reports whether the loop must
+// stop, along with the value the loop statement then evaluates to.
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := Eval(body, env)
+
+	switch {
+	case result == BREAK:
+		return NULL, true
+	case result == nil || result == CONTINUE:
+		return nil, false
+	case result.Type() == object.RETURN_VALUE_OBJECT || result.Type() == object.ERROR_OBJ:
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := Eval(fs.Iterable, env)
+	if unwinds(iterable) {
+		return iterable
+	}
+
+	// every round gets its own binding, so closures made in the body keep
+	// the value of that round
+	round := func(value object.Object) (object.Object, bool) {
+		roundEnv := object.NewEnclosedEnvironment(env)
+		roundEnv.Set(fs.Variable.Value, value)
+		return evalLoopBody(fs.Body, roundEnv)
+	}
+
+	switch iterable := iterable.(type) {
+	case *object.Range:
+		// counting up to the end rather than through Len, which may not
+		// fit in an int64
+		for i := iterable.Start; i < iterable.End || i == iterable.End && iterable.Inclusive; i++ {
+			if result, stop := round(&object.Integer{Value: i}); stop {
+				return result
+			}
+			if i == iterable.End {
+				break
+			}
+		}
+	case *object.Array:
+		for i := 0; i < len(iterable.Elements); i++ {
+			if result, stop := round(iterable.Elements[i]); stop {
+				return result
+			}
+		}
+	case *object.String:
+		for _, r := range iterable.Value {
+			if result, stop := round(&object.String{Value: string(r)}); stop {
+				return result
+			}
+		}
+	case *object.Hash:
+		for _, pair := range iterable.OrderedPairs() {
+			if result, stop := round(pair.Key); stop {
+				return result
+			}
+		}
+	default:
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	return NULL
+}
+
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
-	val, ok := env.Get(node.Value)
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+
+	if builtin, ok := lookupBuiltin(node.Value); ok {
+		return builtin
+	}
+
+	return newError("%s", "identifier not found: "+node.Value)
+}
+
+func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	var result []object.Object
+
+	for _, e := range exps {
+		evaluated := Eval(e, env)
+		if unwinds(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+func applyFunction(fn object.Object, args []object.Object) object.Object {
+	switch function := fn.(type) {
+	case *object.Function:
+		if len(args) != len(function.Parameters) {
+			return newError("wrong number of arguments: want=%d, got=%d", len(function.Parameters), len(args))
+		}
+		extendedEnv := extendFunctionEnv(function, args)
+		if !extendedEnv.EnterCall(MaxCallDepth) {
+			return newError("maximum call depth exceeded: %d", MaxCallDepth)
+		}
+		defer extendedEnv.LeaveCall()
+
+		evaluated := Eval(function.Body, extendedEnv)
+		if evaluated == BREAK || evaluated == CONTINUE {
+			return newError("%s outside of a loop", evaluated.Inspect())
+		}
+		return unwrapReturnValue(evaluated)
+	case *object.Builtin:
+		if result := function.Fn(args...); result != nil {
+			return result
+		}
+		return NULL
+	default:
+		return newError("not a function: %s", fn.Type())
+	}
+}
+
+// calleeName is what a call shows in a stack trace: the name the function
+// was called by, or its source text when it was not called by name.
+func calleeName(callee ast.Expression) string {
+	switch callee := callee.(type) {
+	case *ast.Identifier:
+		return callee.Value
+	case *ast.FunctionLiteral:
+		return "anonymous function"
+	default:
+		return callee.String()
+	}
+}
+
+func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+	env := object.NewEnclosedEnvironment(fn.Env)
+
+	for i, param := range fn.Parameters {
+		env.Set(param.Value, args[i])
+	}
+
+	return env
+}
+
+func unwrapReturnValue(obj object.Object) object.Object {
+	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+
+	if obj == nil {
+		return NULL
+	}
+
+	return obj
+}
+
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.ARRAY_OBJ:
+		return newError("array index must be INTEGER, got %s", index.Type())
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError("index operator not supported: %s", left.Type())
+	}
+}
+
+func evalArrayIndexExpression(array, index object.Object) object.Object {
+	elements := array.(*object.Array).Elements
+	idx, ok := arrayOffset(index.(*object.Integer).Value, len(elements))
+	if !ok {
+		return newError("index out of range: %d (length %d)", index.(*object.Integer).Value, len(elements))
+	}
+
+	return elements[idx]
+}
+
+// arrayOffset turns a possibly negative index into an offset into an array
+// of the given length, reporting whether it is in range.
+func arrayOffset(index int64, length int) (int64, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+	return index, index >= 0 && index < int64(length)
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if unwinds(left) {
+		return left
+	}
+
+	array, ok := left.(*object.Array)
+	if !ok {
+		return newError("slice operator not supported: %s", left.Type())
+	}
+	length := int64(len(array.Elements))
+
+	low, err := evalSliceBound(node.Low, env, 0, length)
+	if err != nil {
+		return err
+	}
+	high, err := evalSliceBound(node.High, env, length, length)
+	if err != nil {
+		return err
+	}
+
+	if low < 0 || high > length || low > high {
+		return newError("slice bounds out of range [%d:%d] with length %d", low, high, length)
+	}
+
+	elements := make([]object.Object, high-low)
+	copy(elements, array.Elements[low:high])
+
+	return &object.Array{Elements: elements}
+}
+
+// evalSliceBound evaluates one side of a slice, counting negative bounds
+// from the end and using fallback when the bound is left out.
+func evalSliceBound(exp ast.Expression, env *object.Environment, fallback, length int64) (int64, object.Object) {
+	if exp == nil {
+		return fallback, nil
+	}
+
+	evaluated := Eval(exp, env)
+	if unwinds(evaluated) {
+		return 0, evaluated
+	}
+
+	integer, ok := evaluated.(*object.Integer)
 	if !ok {
-		return newError("%s", "identifier not found: "+node.Value)
+		return 0, newError("slice index must be INTEGER, got %s", evaluated.Type())
+	}
+
+	if integer.Value < 0 {
+		return integer.Value + length, nil
+	}
+	return integer.Value, nil
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if unwinds(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if unwinds(value) {
+			return value
+		}
+
+		hash.Set(hashKey.HashKey(), object.HashPair{Key: key, Value: value})
+	}
+
+	return hash
+}
+
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hash.(*object.Hash).Get(key.HashKey())
+	if !ok {
+		return NULL
+	}
+
+	return pair.Value
+}
+
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	// x += y is x = x + y with the target evaluated once
+	op := ""
+	if node.Token.Type != token.ASSIGN {
+		op = strings.TrimSuffix(node.Token.Literal, "=")
+	}
+
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		value := Eval(node.Value, env)
+		if unwinds(value) {
+			return value
+		}
+
+		if op != "" {
+			current := evalIdentifier(target, env)
+			if isError(current) {
+				return current
+			}
+			value = evalInfixOp(op, current, value, env.CheckedArithmetic())
+			if isError(value) {
+				return value
+			}
+		}
+
+		if !env.Assign(target.Value, value) {
+			return newError("cannot assign to undeclared identifier: %s", target.Value)
+		}
+		return value
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if unwinds(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if unwinds(index) {
+			return index
+		}
+		value := Eval(node.Value, env)
+		if unwinds(value) {
+			return value
+		}
+
+		if op != "" {
+			current := evalIndexExpression(left, index)
+			if isError(current) {
+				return current
+			}
+			value = evalInfixOp(op, current, value, env.CheckedArithmetic())
+			if isError(value) {
+				return value
+			}
+		}
+
+		return assignIndex(left, index, value)
+	default:
+		return newError("cannot assign to %s", node.Target.String())
+	}
+}
+
+func assignIndex(left, index, value object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		integer, ok := index.(*object.Integer)
+		if !ok {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+
+		idx, ok := arrayOffset(integer.Value, len(left.Elements))
+		if !ok {
+			return newError("index out of range: %d (length %d)", integer.Value, len(left.Elements))
+		}
+
+		left.Elements[idx] = value
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+
+		left.Set(key.HashKey(), object.HashPair{Key: index, Value: value})
+	default:
+		return newError("index assignment not supported: %s", left.Type())
 	}
 
-	return val
+	return value
 }
@@ -1,7 +1,10 @@
 package eval
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/guruorgoru/goru-verbal-interpreter/lexer"
@@ -29,6 +32,21 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"5 ** 0", 1},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"~5", -6},
+		{"1 << 10", 1024},
+		{"-16 >> 2", -4},
+		{"1 << 63 >> 63", 1},
+		{"1 + 2 << 1", 6},
 	}
 
 	for _, tt := range tests {
@@ -57,6 +75,14 @@ func TestEvalBoleanExpression(t *testing.T) {
 		{"(1 < 2) == jhuth", false},
 		{"(1 > 2) == satya", false},
 		{"(1 > 2) == jhuth", true},
+		{"1 <= 1", true},
+		{"2 <= 1", false},
+		{"1 >= 1", true},
+		{"1 >= 2", false},
+		{`"abc" < "abd"`, true},
+		{`"b" > "abc"`, true},
+		{`"a" <= "a"`, true},
+		{`"a" >= "b"`, false},
 	}
 
 	for _, tt := range tests {
@@ -65,18 +91,677 @@ func TestEvalBoleanExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		input           string
+		promoted        string
+		expectedMessage string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808", "integer overflow: 9223372036854775807 + 1"},
+		{"-9223372036854775807 - 2", "-9223372036854775809", "integer overflow: -9223372036854775807 - 2"},
+		{"4611686018427387904 * 2", "9223372036854775808", "integer overflow: 4611686018427387904 * 2"},
+		{"2 ** 64", "18446744073709551616", "integer overflow: 2 ** 64"},
+		{"3 << 62", "13835058055282163712", "integer overflow: 3 << 62"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808", "integer overflow: -(-9223372036854775808)"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808", "integer overflow: -9223372036854775808 / -1"},
+	}
+
+	for _, tt := range tests {
+		testBigInt(t, testEval(tt.input), tt.promoted)
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalChecked(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+
+	// results that fit stay exact in checked mode
+	testDeezInts(t, testEvalChecked("9223372036854775806 + 1"), 9223372036854775807)
+	testDeezInts(t, testEvalChecked("-3037000499 * 3037000499"), -9223372030926249001)
+	testDeezInts(t, testEvalChecked("(-2) ** 63"), -9223372036854775808)
+	testDeezInts(t, testEvalChecked("-1 << 63"), -9223372036854775808)
+
+	// compound assignment and calls see the setting too
+	for _, input := range []string{
+		"manau x = 9223372036854775807; x += 1",
+		"manau f = karya(a) { a * a }; f(4294967296)",
+	} {
+		if _, ok := testEvalChecked(input).(*object.Error); !ok {
+			t.Errorf("no error object returned for %q in checked mode", input)
+		}
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"92233720368547758070", "92233720368547758070"},
+		{"0x1_0000_0000_0000_0000", "18446744073709551616"},
+		{"१००००००००००००००००००००", "100000000000000000000"},
+		{"-92233720368547758070", "-92233720368547758070"},
+		{"92233720368547758070 + 1", "92233720368547758071"},
+		{"2 ** 100", "1267650600228229401496703205376"},
+		{"(2 ** 100) / 7", "181092942889747057356671886482"},
+		{"~(2 ** 64)", "-18446744073709551617"},
+		{"(2 ** 64) | 1", "18446744073709551617"},
+		{"1 << 100 >> 36", "18446744073709551616"},
+		{`
+manau factorial = karya(n) {
+	yadi (n < 2) { firta 1; }
+	n * factorial(n - 1)
+};
+factorial(25)`, "15511210043330985984000000"},
+		// the size guards must not overflow on huge exponents and shifts
+		{"1 << 9223372036854775807", "integer too large: 1 << 9223372036854775807"},
+		{"4 ** 4611686018427387904", "integer too large: 4 ** 4611686018427387904"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if errObj, ok := evaluated.(*object.Error); ok {
+			if errObj.Message != tt.expected {
+				t.Errorf("wrong error message for %q. expected=%q, got=%q", tt.input, tt.expected, errObj.Message)
+			}
+			continue
+		}
+		testBigInt(t, evaluated, tt.expected)
+	}
+}
+
+func TestBigIntegerDemotion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"92233720368547758070 - 92233720368547758069", 1},
+		{"(2 ** 64) / (2 ** 10)", 18014398509481984},
+		{"-9223372036854775808", -9223372036854775808},
+		{"(2 ** 70) >> 70", 1},
+		{"-(2 ** 70) >> 100", -1},
+		{"(2 ** 64) & 1", 0},
+		{"(2 ** 100) / (2 ** 40)", 1152921504606846976},
+		{"-(2 ** 64) % 7", -2},
+		{"manau x = 9223372036854775807; x += 1; x -= 1; x", 9223372036854775807},
+	}
+
+	for _, tt := range tests {
+		testDeezInts(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestBigIntegerComparisonAndKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"2 ** 64 == 18446744073709551616", true},
+		{"2 ** 64 != 2 ** 65", true},
+		{"2 ** 64 > 1", true},
+		{"-(2 ** 64) < 1", true},
+		{"2 ** 64 <= 2 ** 64", true},
+		{"2 ** 63 == 9223372036854775807 + 1", true},
+		{`{2 ** 64: satya}[18446744073709551616]`, true},
+	}
+
+	for _, tt := range tests {
+		testDeezBools(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFloatExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{"-2.5", -2.5},
+		{"1.5 + 1.25", 2.75},
+		{"1 + 0.5", 1.5},
+		{"0.5 + 1", 1.5},
+		{"10 / 4.0", 2.5},
+		{"2.0 * 3", 6},
+		{"7.5 % 2", 1.5},
+		{"2 ** 0.5 ** 2", 1.189207115002721},
+		{"2 ** -1.0", 0.5},
+		{"(2 ** 64) * 0.5", 9223372036854775808},
+		{"manau x = 1; x += 0.5; x", 1.5},
+	}
+
+	for _, tt := range tests {
+		testFloat(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFloatSpecialValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 / 0.0", "+Inf"},
+		{"-1 / 0.0", "-Inf"},
+		{"0.0 / 0", "NaN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestFloatComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1.5 < 2", true},
+		{"2 <= 1.5", false},
+		{"1 == 1.0", true},
+		{"0.1 + 0.2 == 0.3", false},
+		{"0.5 != 0.25", true},
+		{"2 ** 64 == 18446744073709551616.0", true},
+		{"manau nan = 0.0 / 0; nan == nan", false},
+		{"manau nan = 0.0 / 0; nan != nan", true},
+		{"manau nan = 0.0 / 0; nan < 1 || nan >= 1", false},
+	}
+
+	for _, tt := range tests {
+		testDeezBools(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFloatInspectRoundTrips(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"3.0", "3.0"},
+		{"0.1 + 0.2", "0.30000000000000004"},
+		{"1e-9", "1e-09"},
+		{"2.5E+3", "2500.0"},
+		{"1e20", "1e+20"},
+		{"-0.0001", "-0.0001"},
+		{"1 / 3.0", "0.3333333333333333"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect for %q. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+
+		again := testEval(evaluated.Inspect())
+		if again.Inspect() != evaluated.Inspect() {
+			t.Errorf("%s did not read back, got=%s", evaluated.Inspect(), again.Inspect())
+		}
+	}
+}
+
+func TestDevanagariProgram(t *testing.T) {
+	input := `
+मानौ क्रमगुणित = कार्य(न) {
+	यदि (न < २) { फिर्ता १; }
+	न * क्रमगुणित(न - १)
+};
+मानौ जम्मा = ०;
+प्रत्येक अ मा १..=५ {
+	यदि (अ == ३ वा झूठ) { जारी; }
+	जम्मा += क्रमगुणित(अ);
+}
+जम्मा`
+
+	testDeezInts(t, testEval(input), 1+2+24+120)
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"satya && satya", true},
+		{"satya && jhuth", false},
+		{"jhuth || satya", true},
+		{"jhuth || jhuth", false},
+		{"1 < 2 && 3 > 2", true},
+		{"satya ra jhuth", false},
+		{"jhuth wa satya", true},
+		{"1 && 2", 2},
+		{"0 && 2", 2},
+		{"1 || 2", 1},
+		{"jhuth || 7", 7},
+		{"manau x = 5; x > 0 && x", 5},
+		// the right operand is skipped when the left one decides
+		{"jhuth && undefined", false},
+		{"satya || undefined", true},
+		{"manau n = 0; manau bump = karya() { n += 1; satya }; jhuth && bump(); satya || bump(); n", 0},
+		{"manau n = 0; manau bump = karya() { n += 1; satya }; satya && bump(); jhuth || bump(); n", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testDeezBools(t, evaluated, expected)
+		case int:
+			testDeezInts(t, evaluated, int64(expected))
+		}
+	}
+}
+
 func TestIfElseExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected any
 	}{
-		{"yadi (satya) { 10 }", 10},
-		{"yadi (jhuth) { 10 }", nil},
-		{"yadi (1) { 10 }", 10},
-		{"yadi (1 < 2) { 10 }", 10},
-		{"yadi (1 > 2) { 10 }", nil},
-		{"yadi (1 > 2) { 10 } else { 20 }", 20},
-		{"yadi (1 < 2) { 10 } else { 20 }", 10},
+		{"yadi (satya) { 10 }", 10},
+		{"yadi (jhuth) { 10 }", nil},
+		{"yadi (1) { 10 }", 10},
+		{"yadi (1 < 2) { 10 }", 10},
+		{"yadi (1 > 2) { 10 }", nil},
+		{"yadi (1 > 2) { 10 } natra { 20 }", 20},
+		{"yadi (1 < 2) { 10 } natra { 20 }", 10},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testDeezInts(t, evaluated, int64(integer))
+		} else {
+			testDeezNulls(t, evaluated)
+		}
+	}
+}
+
+func TestReturnStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"firta 10;", 10},
+		{"firta 10; 9;", 10},
+		{"9; firta 2 + 5; 9;", 7},
+		{"yadi (10 > 1) { yadi (10 > 1) { firta 10; } firta 1; }", 10},
+	}
+	for _, tt := range tests {
+		evalt := testEval(tt.input)
+		testDeezInts(t, evalt, tt.expected)
+	}
+}
+
+func TestLetStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"manau a = 5; a;", 5},
+		{"manau a = 5 * 5; a;", 25},
+		{"manau a = 5; manau b = a; b;", 5},
+		{"manau a = 5; manau b = a; manau c = a + b + 5; c;", 15},
+	}
+
+	for _, tt := range tests {
+		testDeezInts(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStringLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"Namaste Duniya!"`, "Namaste Duniya!"},
+		{`"Namaste" + " " + "Duniya!"`, "Namaste Duniya!"},
+		{`manau naam = "guru"; "naam: " + naam`, "naam: guru"},
+		{`"tab\there"`, "tab\there"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+		}
+
+		if str.Value != tt.expected {
+			t.Errorf("String has wrong value. got=%q, want=%q", str.Value, tt.expected)
+		}
+	}
+}
+
+func TestStringComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" + "b" == "ab"`, true},
+	}
+
+	for _, tt := range tests {
+		testDeezBools(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(result.Elements) != 3 {
+		t.Fatalf("array has wrong num of elements. got=%d", len(result.Elements))
+	}
+
+	testDeezInts(t, result.Elements[0], 1)
+	testDeezInts(t, result.Elements[1], 4)
+	testDeezInts(t, result.Elements[2], 6)
+}
+
+func TestArrayIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"[1, 2, 3][0]", 1},
+		{"[1, 2, 3][1]", 2},
+		{"[1, 2, 3][2]", 3},
+		{"manau i = 0; [1][i];", 1},
+		{"[1, 2, 3][1 + 1];", 3},
+		{"manau myArray = [1, 2, 3]; myArray[2];", 3},
+		{"manau myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+	}
+
+	for _, tt := range tests {
+		testDeezInts(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestArraySliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3, 4][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4][2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][:]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4][-2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][1:-1]", "[2, 3]"},
+		{"[1, 2, 3, 4][2:2]", "[]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong slice for %q. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestHashLiterals(t *testing.T) {
+	input := `manau two = "two";
+	{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 / 2,
+		4: 4,
+		satya: 5,
+		jhuth: 6
+	}`
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		TRUE.HashKey():                             5,
+		FALSE.HashKey():                            6,
+	}
+
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
+	}
+
+	for expectedKey, expectedValue := range expected {
+		pair, ok := result.Get(expectedKey)
+		if !ok {
+			t.Errorf("no pair for given key in Pairs")
+		}
+
+		testDeezInts(t, pair.Value, expectedValue)
+	}
+}
+
+func TestHashKeys(t *testing.T) {
+	hello1 := &object.String{Value: "Hello World"}
+	hello2 := &object.String{Value: "Hello World"}
+	diff := &object.String{Value: "My name is johnny"}
+
+	if hello1.HashKey() != hello2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if hello1.HashKey() == diff.HashKey() {
+		t.Errorf("strings with different content have same hash keys")
+	}
+
+	if (&object.Integer{Value: 1}).HashKey() == TRUE.HashKey() {
+		t.Errorf("integer 1 and satya have same hash keys")
+	}
+}
+
+func TestHashInsertionOrder(t *testing.T) {
+	input := `manau h = {"z": 1, "a": 2, 10: 3}; h["m"] = 4; h["z"] = 5; h;`
+
+	expected := "{z: 5, a: 2, 10: 3, m: 4}"
+	if got := testEval(input).Inspect(); got != expected {
+		t.Errorf("wrong order. expected=%s, got=%s", expected, got)
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`manau key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{satya: 5}[satya]`, 5},
+		{`{jhuth: 5}[jhuth]`, 5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testDeezInts(t, evaluated, int64(integer))
+		} else {
+			testDeezNulls(t, evaluated)
+		}
+	}
+}
+
+func TestIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`manau h = {}; h["k"] = 2; h["k"]`, 2},
+		{`manau h = {"k": 1}; h["k"] = h["k"] + 2; h["k"]`, 3},
+		{"manau a = [1, 2, 3]; a[0] = 10; a[0]", 10},
+		{"manau a = [1, 2, 3]; a[-1] = 30; a[2]", 30},
+		{"manau a = [1, 2]; manau b = [3]; a[0] = b[0] = 7; a[0] + b[0]", 14},
+	}
+
+	for _, tt := range tests {
+		testDeezInts(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"manau x = 1; x = 2; x", 2},
+		{"manau x = 1; x = 5", 5},
+		{"manau x = 1; manau y = 1; x = y = 3; x + y", 6},
+		{"manau x = 10; x += 5; x", 15},
+		{"manau x = 10; x -= 5; x", 5},
+		{"manau x = 10; x *= 5; x", 50},
+		{"manau x = 10; x /= 5; x", 2},
+		{"manau x = 10; x %= 4; x", 2},
+		{"manau x = 1; manau set = karya() { x = 7; }; set(); x", 7},
+		{"manau x = 1; manau shadow = karya() { manau x = 2; x = 3; }; shadow(); x", 1},
+		{"manau counter = karya() { manau n = 0; karya() { n += 1; } }; manau c = counter(); c(); c(); c()", 3},
+		{"manau i = 0; jabasamma (i < 5) { i += 1; } i", 5},
+		{`manau h = {"k": 1}; h["k"] += 2; h["k"]`, 3},
+		{"manau a = [1, 2, 3]; a[-1] *= 10; a[2]", 30},
+		{"manau a = [0]; manau i = 0; a[i] += 4; a[0]", 4},
+	}
+
+	for _, tt := range tests {
+		testDeezInts(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStringCompoundAssignment(t *testing.T) {
+	evaluated := testEval(`manau s = "Namaste"; s += ", Duniya"; s`)
+
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Namaste, Duniya" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestWhileStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"manau i = 0; jabasamma (i < 5) { manau i = i + 1; } i", 5},
+		{"manau i = 0; jabasamma (jhuth) { manau i = i + 1; } i", 0},
+		{"jabasamma (jhuth) { 1 }", nil},
+		{"manau i = 0; jabasamma (satya) { manau i = i + 1; yadi (i == 3) { roka; } } i", 3},
+		{`
+manau i = 0;
+manau sum = 0;
+jabasamma (i < 10) {
+	manau i = i + 1;
+	yadi (i > 5) { jari; }
+	manau sum = sum + i;
+}
+sum`, 15},
+		{`
+manau i = 0;
+manau count = 0;
+jabasamma (i < 3) {
+	manau i = i + 1;
+	manau j = 0;
+	jabasamma (satya) {
+		manau j = j + 1;
+		yadi (j > 2) { roka; }
+		manau count = count + 1;
+	}
+}
+count`, 6},
+		{`
+manau find = karya(xs, x) {
+	manau i = 0;
+	jabasamma (i < len(xs)) {
+		yadi (xs[i] == x) { firta i; }
+		manau i = i + 1;
+	}
+	firta -1;
+};
+find([4, 5, 6], 6)`, 2},
+		// roka and jari in a block used as a value still reach the loop
+		{"manau n = 0; jabasamma (n < 3) { n += 1; manau x = yadi (satya) { roka; } natra { 1 }; } n", 1},
+		{"manau n = 0; manau sum = 0; jabasamma (n < 3) { n += 1; sum += [yadi (n == 2) { jari; } natra { n }][0]; } sum", 4},
+		{"manau n = 0; jabasamma (n < 3) { n += 1; len(yadi (satya) { roka; }); } n", 1},
+		{"manau f = karya() { jabasamma (satya) { manau x = 1 + yadi (satya) { firta 7; }; } }; f()", 7},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testDeezInts(t, evaluated, int64(integer))
+		} else {
+			testDeezNulls(t, evaluated)
+		}
+	}
+}
+
+func TestForStatements(t *testing.T) {
+	// each test appends the values it wants to see to the hash out
+	tests := []struct {
+		loop     string
+		expected string
+	}{
+		{"pratyek x ma 0..4 { out[len(out)] = x; }", "{0: 0, 1: 1, 2: 2, 3: 3}"},
+		{"pratyek x ma 1..=3 { out[len(out)] = x; }", "{0: 1, 1: 2, 2: 3}"},
+		{"pratyek x ma 3..1 { out[len(out)] = x; }", "{}"},
+		{"pratyek x ma [5, 6] { out[len(out)] = x * 2; }", "{0: 10, 1: 12}"},
+		{`pratyek c ma "aनम" { out[len(out)] = c; }`, "{0: a, 1: न, 2: म}"},
+		{`pratyek k ma {"z": 1, "a": 2} { out[len(out)] = k; }`, "{0: z, 1: a}"},
+		{"pratyek x ma 0..10 { yadi (x == 3) { roka; } out[len(out)] = x; }", "{0: 0, 1: 1, 2: 2}"},
+		{"pratyek x ma 0..5 { yadi (x == 1) { jari; } out[len(out)] = x; }", "{0: 0, 1: 2, 2: 3, 3: 4}"},
+		{"pratyek x ma 0..2 { pratyek y ma 0..2 { out[len(out)] = x * 10 + y; } }", "{0: 0, 1: 1, 2: 10, 3: 11}"},
+		{"pratyek x ma 0..3 { out[x] = karya() { x }; } manau out = {0: out[0](), 1: out[2]()};", "{0: 0, 1: 2}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval("manau out = {}; " + tt.loop + " out")
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.loop, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestForStatementValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"pratyek x ma 0..3 { x }", nil},
+		{"manau f = karya() { pratyek x ma 1..100 { yadi (x * x > 50) { firta x; } } }; f()", 8},
+		{"len(0..10)", 10},
+		{"len(0..=10)", 11},
+		{"len(5..1)", 0},
 	}
 
 	for _, tt := range tests {
@@ -90,31 +775,64 @@ func TestIfElseExpressions(t *testing.T) {
 	}
 }
 
-func TestReturnStatements(t *testing.T) {
+func TestHugeRanges(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected int64
+		expected string
 	}{
-		{"firta 10;", 10},
-		{"firta 10; 9;", 10},
-		{"9; firta 2 + 5; 9;", 7},
-		{"yadi (10 > 1) { yadi (10 > 1) { firta 10; } firta 1; }", 10},
+		{"len(0..=9223372036854775807)", "9223372036854775808"},
+		{"len((-9223372036854775807 - 1)..=9223372036854775807)", "18446744073709551616"},
+		{"len((-9223372036854775807 - 1)..9223372036854775807)", "18446744073709551615"},
 	}
+
 	for _, tt := range tests {
-		evalt := testEval(tt.input)
-		testDeezInts(t, evalt, tt.expected)
+		testBigInt(t, testEval(tt.input), tt.expected)
 	}
+
+	// stepping up to MaxInt64 must neither stop early nor wrap around
+	testDeezInts(t, testEval("manau n = 0; pratyek i ma 9223372036854775805..=9223372036854775807 { n += 1 }; n"), 3)
+	testDeezInts(t, testEval("manau n = 0; pratyek i ma 9223372036854775805..9223372036854775807 { n += 1 }; n"), 2)
+	testDeezInts(t, testEval("manau last = 0; pratyek i ma 9223372036854775806..=9223372036854775807 { last = i }; last"), 9223372036854775807)
+	testDeezInts(t, testEval("manau n = 0; pratyek i ma (-9223372036854775807 - 1)..=9223372036854775807 { yadi (n == 5) { roka; } n += 1 }; n"), 5)
+
+	// a count is not arithmetic, so checked mode leaves it exact
+	testBigInt(t, testEvalChecked("len(0..=9223372036854775807)"), "9223372036854775808")
+	testDeezInts(t, testEvalChecked("len(0..9223372036854775807)"), 9223372036854775807)
 }
 
-func TestLetStatement(t *testing.T) {
+func TestFunctionObject(t *testing.T) {
+	input := "karya(x) { x + 2; };"
+
+	evaluated := testEval(input)
+	fn, ok := evaluated.(*object.Function)
+	if !ok {
+		t.Fatalf("object is not Function. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(fn.Parameters) != 1 {
+		t.Fatalf("function has wrong parameters. Parameters=%+v", fn.Parameters)
+	}
+
+	if fn.Parameters[0].String() != "x" {
+		t.Fatalf("parameter is not 'x'. got=%q", fn.Parameters[0])
+	}
+
+	if fn.Body.String() != "(x + 2)" {
+		t.Fatalf("body is not %q. got=%q", "(x + 2)", fn.Body.String())
+	}
+}
+
+func TestFunctionApplication(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected int64
 	}{
-		{"manau a = 5; a;", 5},
-		{"manau a = 5 * 5; a;", 25},
-		{"manau a = 5; manau b = a; b;", 5},
-		{"manau a = 5; manau b = a; manau c = a + b + 5; c;", 15},
+		{"manau identity = karya(x) { x; }; identity(5);", 5},
+		{"manau identity = karya(x) { firta x; }; identity(5);", 5},
+		{"manau double = karya(x) { x * 2; }; double(5);", 10},
+		{"manau add = karya(x, y) { x + y; }; add(5, 5);", 10},
+		{"manau add = karya(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"karya(x) { x; }(5)", 5},
 	}
 
 	for _, tt := range tests {
@@ -122,6 +840,283 @@ func TestLetStatement(t *testing.T) {
 	}
 }
 
+func TestClosures(t *testing.T) {
+	input := `
+manau newAdder = karya(x) {
+	karya(y) { x + y };
+};
+
+manau addTwo = newAdder(2);
+addTwo(2);`
+
+	testDeezInts(t, testEval(input), 4)
+}
+
+func TestRecursiveFunction(t *testing.T) {
+	input := `
+manau fact = karya(n) {
+	yadi (n < 2) { firta 1; }
+	n * fact(n - 1);
+};
+fact(5);`
+
+	testDeezInts(t, testEval(input), 120)
+}
+
+func TestErrorPositions(t *testing.T) {
+	input := "manau x = 1;\nmanau y = x +\n  (5 + satya);"
+
+	l := lexer.NewFile("script.goru", input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	errObj, ok := Eval(program, object.NewEnvironment()).(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned")
+	}
+
+	expected := "ERROR: script.goru:3:4: type mismatch: INTEGER + BOOLEAN"
+	if errObj.Inspect() != expected {
+		t.Errorf("wrong error. expected=%q, got=%q", expected, errObj.Inspect())
+	}
+}
+
+func TestErrorStack(t *testing.T) {
+	input := `manau divide = karya(a, b) { a / b };
+manau average = karya(xs) { divide(xs[0] + xs[1], len(xs) - 2) };
+manau h = {"avg": average};
+h["avg"]([1, 2]);`
+
+	errObj, ok := testEval(input).(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned")
+	}
+
+	expected := []struct {
+		function string
+		pos      string
+	}{
+		{"divide", "2:29"},
+		{`(h["avg"])`, "4:1"},
+	}
+	if len(errObj.Stack) != len(expected) {
+		t.Fatalf("wrong stack length. expected=%d, got=%d: %v", len(expected), len(errObj.Stack), errObj.Stack)
+	}
+	for i, frame := range expected {
+		got := errObj.Stack[i]
+		if got.Function != frame.function || got.Pos.String() != frame.pos {
+			t.Errorf("wrong frame %d. expected=%s at %s, got=%s at %s", i, frame.function, frame.pos, got.Function, got.Pos)
+		}
+	}
+
+	// errors about the call itself, or raised by builtins, add no frame
+	for _, input := range []string{"manau f = karya(a) { a }; f(1, 2)", `len(1)`} {
+		errObj, ok := testEval(input).(*object.Error)
+		if !ok {
+			t.Fatalf("no error object returned for %q", input)
+		}
+		if len(errObj.Stack) != 0 {
+			t.Errorf("unexpected stack for %q: %v", input, errObj.Stack)
+		}
+	}
+}
+
+func TestMaxCallDepth(t *testing.T) {
+	env := object.NewEnvironment()
+	program := parser.New(lexer.New("manau f = karya(n) { f(n + 1) }; f(0)")).ParseProgram()
+
+	errObj, ok := Eval(program, env).(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned")
+	}
+	if errObj.Message != "maximum call depth exceeded: 10000" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+	if len(errObj.Stack) != MaxCallDepth {
+		t.Errorf("wrong stack length. expected=%d, got=%d", MaxCallDepth, len(errObj.Stack))
+	}
+
+	// the depth is given back as the error unwinds, so the same environment
+	// can go as deep again
+	program = parser.New(lexer.New("manau g = karya(n) { yadi (n > 0) { g(n - 1) } natra { 7 } }; g(9999)")).ParseProgram()
+	testDeezInts(t, Eval(program, env), 7)
+}
+
+func TestConcurrentEvaluations(t *testing.T) {
+	// each evaluation keeps its own call depth, so together they may go
+	// deeper than MaxCallDepth
+	input := "manau f = karya(n) { yadi (n > 0) { f(n - 1) } natra { 7 } }; f(9000)"
+
+	results := make(chan object.Object)
+	for i := 0; i < 4; i++ {
+		checked := i%2 == 0
+		go func() {
+			env := object.NewEnvironment()
+			env.SetCheckedArithmetic(checked)
+			results <- Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+		}()
+	}
+
+	for i := 0; i < 4; i++ {
+		testDeezInts(t, <-results, 7)
+	}
+}
+
+func TestThrowAndCatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`prayas { fyak "oops"; 1 } samata (e) { e }`, "oops"},
+		{`prayas { fyak {"code": 42}; } samata (e) { e["code"] }`, "42"},
+		{`prayas { 1 + 1 } samata (e) { "unreached" }`, "2"},
+		{`prayas { 1 + satya } samata (e) { e["message"] }`, "type mismatch: INTEGER + BOOLEAN"},
+		{`prayas { nope } samata (e) { e["message"] }`, "identifier not found: nope"},
+		{"manau x = 1;\nprayas {\n  x + nope\n} samata (e) { e[\"line\"] * 100 + e[\"column\"] }", "307"},
+		{`manau f = karya() { fyak "deep" }; manau g = karya() { f() }; prayas { g() } samata (e) { e }`, "deep"},
+		{`prayas { prayas { fyak 1 } samata (e) { fyak e + 1 } } samata (e) { e }`, "2"},
+		{`prayas { fyak 1 } samata (e) { e = e + 1; e }`, "2"},
+		{`manau f = karya(n) { f(n + 1) }; prayas { f(0) } samata (e) { e["message"] }`, "maximum call depth exceeded: 10000"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestUncaughtThrow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`fyak "oops"`, "ERROR: 1:1: uncaught exception: oops"},
+		{`manau x = 1; fyak [x, 2]`, "ERROR: 1:14: uncaught exception: [1, 2]"},
+		{`prayas { fyak 1 } antama { 2 }`, "ERROR: 1:10: uncaught exception: 1"},
+		{`prayas { fyak 1 } samata (e) { e + satya }`, "ERROR: 1:32: type mismatch: INTEGER + BOOLEAN"},
+		{`fyak nope`, "ERROR: 1:6: identifier not found: nope"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestFinally(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// normal exit
+		{`manau log = ""; prayas { log += "t" } antama { log += "f" }; log`, "tf"},
+		// firta from inside the prayas and the samata blocks
+		{`manau log = "";
+manau f = karya() { prayas { firta "r" } antama { log += "f" } };
+f() + log`, "rf"},
+		{`manau log = "";
+manau f = karya() { prayas { fyak 1 } samata (e) { firta "c" } antama { log += "f" } };
+f() + log`, "cf"},
+		// error unwinding, caught further up
+		{`manau log = "";
+manau f = karya() { prayas { 1 + satya } antama { log += "f" } };
+prayas { f() } samata (e) { log += "c" };
+log`, "fc"},
+		// roka and jari in a loop
+		{`manau log = "";
+pratyek i ma 0..4 {
+	prayas { yadi (i == 1) { jari; } yadi (i == 3) { roka; } log += "b" } antama { log += "f" }
+}
+log`, "bffbff"},
+		// an antama block that unwinds replaces the outcome
+		{`manau f = karya() { prayas { firta "r" } antama { firta "f" } }; f()`, "f"},
+		{`prayas { prayas { fyak "inner" } antama { fyak "outer" } } samata (e) { e }`, "outer"},
+		{`manau f = karya() { prayas { fyak "t" } antama { firta "f" } }; f()`, "f"},
+		// otherwise its value is dropped
+		{`prayas { 1 } antama { 2 }`, "1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len("hello world")`, 11},
+		{`len("नमस्ते")`, 6},
+		{`len([1, 2, 3])`, 3},
+		{`len({"a": 1})`, 1},
+		{`len(1)`, "argument to `len` not supported, got INTEGER"},
+		{`len("one", "two")`, "wrong number of arguments to `len`: want=1, got=2"},
+		{`manau len = karya(x) { 42 }; len("a")`, 42},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testDeezInts(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestPrintAndReadBuiltins(t *testing.T) {
+	var out bytes.Buffer
+	oldStdout, oldStdin := Stdout, Stdin
+	Stdout, Stdin = &out, bufio.NewReader(strings.NewReader("guru\n"))
+	defer func() { Stdout, Stdin = oldStdout, oldStdin }()
+
+	evaluated := testEval(`manau naam = padh("naam? "); chhap("namaste", naam, 1); padh();`)
+	testDeezNulls(t, evaluated)
+
+	expected := "naam? namaste guru 1\n"
+	if out.String() != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestRegisterBuiltin(t *testing.T) {
+	RegisterBuiltin("dohoro", func(args ...object.Object) object.Object {
+		if len(args) != 1 || args[0].Type() != object.INTEGER_OBJ {
+			return &object.Error{Message: "dohoro wants one integer"}
+		}
+		return &object.Integer{Value: args[0].(*object.Integer).Value * 2}
+	})
+
+	testDeezInts(t, testEval("dohoro(21)"), 42)
+
+	errObj, ok := testEval("dohoro(satya)").(*object.Error)
+	if !ok || errObj.Message != "dohoro wants one integer" {
+		t.Errorf("expected error from registered builtin. got=%+v", errObj)
+	}
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -134,6 +1129,21 @@ func testEval(input string) object.Object {
 	return Eval(program, object.NewEnvironment())
 }
 
+// testEvalChecked evaluates input with int64 overflow reported as an error.
+func testEvalChecked(input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		panic(fmt.Sprintf("parser errors: %v", p.Errors()))
+	}
+
+	env := object.NewEnvironment()
+	env.SetCheckedArithmetic(true)
+	return Eval(program, env)
+}
+
 func testDeezInts(t *testing.T, obj object.Object, expected int64) bool {
 	result, ok := obj.(*object.Integer)
 
@@ -150,6 +1160,38 @@ func testDeezInts(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+func testBigInt(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.BigInt)
+
+	if !ok {
+		t.Errorf("object is not a BigInt, got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value.String() != expected {
+		t.Errorf("object has wrong value, got=%s, want=%s", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func testFloat(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+
+	if !ok {
+		t.Errorf("object is not a Float, got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value, got=%g, want=%g", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
 func testDeezBools(t *testing.T, obj object.Object, expected bool) bool {
 	result, ok := obj.(*object.Boolean)
 
@@ -218,6 +1260,146 @@ firta 1;
 			"foobar",
 			"identifier not found: foobar",
 		},
+		{
+			"manau f = karya(x) { x; }; f(1, 2);",
+			"wrong number of arguments: want=1, got=2",
+		},
+		{
+			"5(1)",
+			"not a function: INTEGER",
+		},
+		{
+			`"Namaste" - "Duniya"`,
+			"unknown operator: STRING - STRING",
+		},
+		{
+			`"a" + 1`,
+			"type mismatch: STRING + INTEGER",
+		},
+		{
+			"x = 1",
+			"cannot assign to undeclared identifier: x",
+		},
+		{
+			"2 ** -1",
+			"negative exponent: 2 ** -1",
+		},
+		{
+			"1 / 0",
+			"division by zero: 1 / 0",
+		},
+		{
+			"(2 ** 64) / 0",
+			"division by zero: 18446744073709551616 / 0",
+		},
+		{
+			"2 ** (2 ** 64)",
+			"integer too large: 2 ** 18446744073709551616",
+		},
+		{
+			"1 << (2 ** 40)",
+			"integer too large: 1 << 1099511627776",
+		},
+		{
+			"(2 ** 64) .. 1",
+			"unknown operator: BIGINT .. INTEGER",
+		},
+		{
+			"1.5 & 1",
+			"unknown operator: FLOAT & INTEGER",
+		},
+		{
+			"~1.5",
+			"unknown operator: ~FLOAT",
+		},
+		{
+			`1.5 + "a"`,
+			"type mismatch: FLOAT + STRING",
+		},
+		{
+			"manau x = 5; x %= 0",
+			"division by zero: 5 % 0",
+		},
+		{
+			"1 << -1",
+			"negative shift count: -1",
+		},
+		{
+			"1 >> -3",
+			"negative shift count: -3",
+		},
+		{
+			"~satya",
+			"unknown operator: ~BOOLEAN",
+		},
+		{
+			"satya & jhuth",
+			"unknown operator: BOOLEAN & BOOLEAN",
+		},
+		{
+			"y += 1",
+			"identifier not found: y",
+		},
+		{
+			`manau s = "a"; s -= "b"`,
+			"unknown operator: STRING - STRING",
+		},
+		{
+			`manau h = {}; h["k"] += 1`,
+			"type mismatch: NULL + INTEGER",
+		},
+		{
+			"[1, 2, 3][3]",
+			"index out of range: 3 (length 3)",
+		},
+		{
+			"[1, 2, 3][-4]",
+			"index out of range: -4 (length 3)",
+		},
+		{
+			`[1, 2, 3]["a"]`,
+			"array index must be INTEGER, got STRING",
+		},
+		{
+			"5[0]",
+			"index operator not supported: INTEGER",
+		},
+		{
+			"[1, 2, 3][2:1]",
+			"slice bounds out of range [2:1] with length 3",
+		},
+		{
+			"[1, 2, 3][0:4]",
+			"slice bounds out of range [0:4] with length 3",
+		},
+		{
+			`{"name": "Monkey"}[karya(x) { x }];`,
+			"unusable as hash key: FUNCTION",
+		},
+		{
+			`{[1]: 2}`,
+			"unusable as hash key: ARRAY",
+		},
+		{
+			"manau a = [1]; a[1] = 2;",
+			"index out of range: 1 (length 1)",
+		},
+		{
+			`manau s = "abc"; s[0] = "x";`,
+			"index assignment not supported: STRING",
+		},
+		{
+			"manau i = 0; jabasamma (i < 10) { manau i = i + satya; }",
+			"type mismatch: INTEGER + BOOLEAN",
+		},
+		{
+			"pratyek x ma 5 { x }",
+			"cannot iterate over INTEGER",
+		},
+		{
+			"pratyek x ma 0..3 { x + satya }",
+			"type mismatch: INTEGER + BOOLEAN",
+		},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+)
+
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat converts any number to the nearest float64, BigInts too large for
+// one become infinities.
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInt:
+		value, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return value
+	default:
+		return obj.(*object.Float).Value
+	}
+}
+
+// evalFloatInfixOp applies op to numbers of which at least one is a float.
+// It follows IEEE 754, so dividing by zero gives an infinity or NaN, and NaN
+// compares unequal to everything including itself.
+func evalFloatInfixOp(op string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch op {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
+	case "<":
+		return inputBoolToBoolObj(leftVal < rightVal)
+	case ">":
+		return inputBoolToBoolObj(leftVal > rightVal)
+	case "<=":
+		return inputBoolToBoolObj(leftVal <= rightVal)
+	case ">=":
+		return inputBoolToBoolObj(leftVal >= rightVal)
+	case "==":
+		return inputBoolToBoolObj(leftVal == rightVal)
+	case "!=":
+		return inputBoolToBoolObj(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	}
+}
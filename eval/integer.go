@@ -0,0 +1,172 @@
+package eval
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+)
+
+// maxBigIntBits bounds the size of BigInts made by ** and <<, so that a typo
+// like 2 ** 10 ** 12 reports an error rather than eating all memory.
+const maxBigIntBits = 1 << 26
+
+// The helpers below return the wrapped result together with whether it is
+// exact.
+
+func addInt(a, b int64) (int64, bool) {
+	c := a + b
+	return c, (a^c)&(b^c) >= 0
+}
+
+func subInt(a, b int64) (int64, bool) {
+	c := a - b
+	return c, (a^b)&(a^c) >= 0
+}
+
+func mulInt(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return c, false
+	}
+	return c, c/b == a
+}
+
+// powInt raises base to a non-negative exponent by squaring.
+func powInt(base, exponent int64) (int64, bool) {
+	result, exact := int64(1), true
+	for exponent > 0 {
+		var ok bool
+		if exponent&1 == 1 {
+			result, ok = mulInt(result, base)
+			exact = exact && ok
+		}
+		exponent >>= 1
+		if exponent > 0 {
+			base, ok = mulInt(base, base)
+			exact = exact && ok
+		}
+	}
+	return result, exact
+}
+
+// shlInt shifts a left by a non-negative count.
+func shlInt(a, count int64) (int64, bool) {
+	if count >= 64 {
+		return 0, a == 0
+	}
+	c := a << uint64(count)
+	return c, c>>uint64(count) == a
+}
+
+func negInt(a int64) (int64, bool) {
+	return -a, a != math.MinInt64
+}
+
+// integerResult wraps the result of an integer operation. An inexact one is
+// redone with BigInts, or is an overflow error in checked mode.
+func integerResult(value int64, exact bool, op string, left, right int64, checked bool) object.Object {
+	if exact {
+		return &object.Integer{Value: value}
+	}
+	if checked {
+		return newError("integer overflow: %d %s %d", left, op, right)
+	}
+	return evalBigIntInfixOp(op, &object.Integer{Value: left}, &object.Integer{Value: right})
+}
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIGINT_OBJ
+}
+
+func toBigInt(obj object.Object) *big.Int {
+	if integer, ok := obj.(*object.Integer); ok {
+		return big.NewInt(integer.Value)
+	}
+	return obj.(*object.BigInt).Value
+}
+
+// bigInteger demotes value to an Integer when it fits.
+func bigInteger(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInt{Value: value}
+}
+
+// evalBigIntInfixOp applies op to integers of which at least one does not fit
+// in an int64.
+func evalBigIntInfixOp(op string, left, right object.Object) object.Object {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+
+	switch op {
+	case "+":
+		return bigInteger(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return bigInteger(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return bigInteger(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero: %s / 0", leftVal)
+		}
+		return bigInteger(new(big.Int).Quo(leftVal, rightVal))
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero: %s %% 0", leftVal)
+		}
+		return bigInteger(new(big.Int).Rem(leftVal, rightVal))
+	case "**":
+		if rightVal.Sign() < 0 {
+			return newError("negative exponent: %s ** %s", leftVal, rightVal)
+		}
+		if leftVal.CmpAbs(big.NewInt(1)) > 0 && (!rightVal.IsInt64() || rightVal.Int64() > maxBigIntBits/int64(leftVal.BitLen()-1)) {
+			return newError("integer too large: %s ** %s", leftVal, rightVal)
+		}
+		return bigInteger(new(big.Int).Exp(leftVal, rightVal, nil))
+	case "&":
+		return bigInteger(new(big.Int).And(leftVal, rightVal))
+	case "|":
+		return bigInteger(new(big.Int).Or(leftVal, rightVal))
+	case "^":
+		return bigInteger(new(big.Int).Xor(leftVal, rightVal))
+	case "<<":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s", rightVal)
+		}
+		if leftVal.Sign() == 0 {
+			return &object.Integer{Value: 0}
+		}
+		if !rightVal.IsInt64() || rightVal.Int64() > maxBigIntBits-int64(leftVal.BitLen()) {
+			return newError("integer too large: %s << %s", leftVal, rightVal)
+		}
+		return bigInteger(new(big.Int).Lsh(leftVal, uint(rightVal.Int64())))
+	case ">>":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s", rightVal)
+		}
+		if !rightVal.IsInt64() || rightVal.Int64() > int64(leftVal.BitLen()) {
+			// everything is shifted out, leaving only the sign
+			return &object.Integer{Value: int64(min(leftVal.Sign(), 0))}
+		}
+		return bigInteger(new(big.Int).Rsh(leftVal, uint(rightVal.Int64())))
+	case "<":
+		return inputBoolToBoolObj(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return inputBoolToBoolObj(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return inputBoolToBoolObj(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return inputBoolToBoolObj(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return inputBoolToBoolObj(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return inputBoolToBoolObj(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	}
+}
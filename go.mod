@@ -1,3 +1,10 @@
 module github.com/guruorgoru/goru-verbal-interpreter
 
 go 1.24.6
+
+require (
+	github.com/mattn/go-runewidth v0.0.3
+	github.com/peterh/liner v1.2.2
+)
+
+require golang.org/x/sys v0.0.0-20211117180635-dee7805ff2e1 // indirect
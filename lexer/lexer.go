@@ -1,39 +1,110 @@
 package lexer
 
 import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/guruorgoru/goru-verbal-interpreter/token"
 )
 
+// Lexer reads UTF-8 source a rune at a time. Offsets count bytes, columns
+// count runes.
+
 type Lexer struct {
 	input        string
+	filename     string
 	position     int
 	readPosition int
-	ch           byte
+	ch           rune
+	line         int
+	column       int
+	keepComments bool
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewFile("", input)
+}
+
+// NewFile creates a lexer whose token positions carry filename.
+func NewFile(filename, input string) *Lexer {
+	l := &Lexer{input: input, filename: filename, line: 1}
 	l.readChar()
+	l.skipShebang()
 	return l
 }
 
 func (lex *Lexer) readChar() {
+	if lex.ch == '\n' {
+		lex.line++
+		lex.column = 1
+	} else {
+		lex.column++
+	}
+
+	width := 1
 	if lex.readPosition >= len(lex.input) {
 		lex.ch = 0
 	} else {
-		lex.ch = lex.input[lex.readPosition]
+		lex.ch, width = utf8.DecodeRuneInString(lex.input[lex.readPosition:])
 	}
 	lex.position = lex.readPosition
-	lex.readPosition++
+	lex.readPosition += width
+}
+
+// skipShebang skips a leading "#!" line so scripts can be made executable.
+func (lex *Lexer) skipShebang() {
+	if lex.ch != '#' || lex.peekAtNextChar() != '!' {
+		return
+	}
+	for lex.ch != '\n' && lex.ch != 0 {
+		lex.readChar()
+	}
+}
+
+func (lex *Lexer) currentPosition() token.Position {
+	offset := lex.position
+	if offset > len(lex.input) {
+		offset = len(lex.input)
+	}
+	return token.Position{
+		Filename: lex.filename,
+		Offset:   offset,
+		Line:     lex.line,
+		Column:   lex.column,
+	}
+}
+
+func (lex *Lexer) locate(tok token.Token, start token.Position) token.Token {
+	tok.Pos = start
+	tok.End = lex.currentPosition()
+	return tok
 }
 
 func (lex *Lexer) NextToken() token.Token {
 	var tok token.Token
 
-	for lex.ch == ' ' || lex.ch == '\t' || lex.ch == '\n' || lex.ch == '\r' {
-		lex.readChar()
+	for {
+		for lex.ch == ' ' || lex.ch == '\t' || lex.ch == '\n' || lex.ch == '\r' {
+			lex.readChar()
+		}
+		if lex.ch != '/' || (lex.peekAtNextChar() != '/' && lex.peekAtNextChar() != '*') {
+			break
+		}
+
+		start := lex.currentPosition()
+		literal, ok := lex.readComment()
+		switch {
+		case !ok:
+			return lex.locate(token.Token{Type: token.ILLEGAL, Literal: literal}, start)
+		case lex.keepComments:
+			return lex.locate(token.Token{Type: token.COMMENT, Literal: literal}, start)
+		}
 	}
 
+	start := lex.currentPosition()
+
 	switch lex.ch {
 	case '=':
 		if lex.peekAtNextChar() == '=' {
@@ -45,7 +116,7 @@ func (lex *Lexer) NextToken() token.Token {
 			tok = token.Token{Type: token.ASSIGN, Literal: string(lex.ch)}
 		}
 	case '+':
-		tok = token.Token{Type: token.PLUS, Literal: string(lex.ch)}
+		tok = lex.withEquals(token.PLUS, token.PLUSASSIGN)
 	case '(':
 		tok = token.Token{Type: token.LEFTPARENTHESIS, Literal: string(lex.ch)}
 	case ')':
@@ -56,8 +127,29 @@ func (lex *Lexer) NextToken() token.Token {
 		tok = token.Token{Type: token.LEFTBRACES, Literal: string(lex.ch)}
 	case '}':
 		tok = token.Token{Type: token.RIGHTBRACES, Literal: string(lex.ch)}
+	case '[':
+		tok = token.Token{Type: token.LEFTBRACKET, Literal: string(lex.ch)}
+	case ']':
+		tok = token.Token{Type: token.RIGHTBRACKET, Literal: string(lex.ch)}
 	case ';':
 		tok = token.Token{Type: token.SEMICOLON, Literal: string(lex.ch)}
+	case ':':
+		tok = token.Token{Type: token.COLON, Literal: string(lex.ch)}
+	case '.':
+		if isDigit(lex.peekAtNextChar()) {
+			tok.Literal, tok.Type = lex.readNumber()
+			return lex.locate(tok, start)
+		} else if lex.peekAtNextChar() == '.' {
+			lex.readChar()
+			if lex.peekAtNextChar() == '=' {
+				lex.readChar()
+				tok = token.Token{Type: token.DOTDOTEQ, Literal: "..="}
+			} else {
+				tok = token.Token{Type: token.DOTDOT, Literal: ".."}
+			}
+		} else {
+			tok = token.Token{Type: token.ILLEGAL, Literal: string(lex.ch)}
+		}
 	case '!':
 		if lex.peekAtNextChar() == '=' {
 			currentChar := lex.ch
@@ -68,67 +160,300 @@ func (lex *Lexer) NextToken() token.Token {
 			tok = token.Token{Type: token.BANG, Literal: string(lex.ch)}
 		}
 	case '-':
-		tok = token.Token{Type: token.MINUS, Literal: string(lex.ch)}
+		tok = lex.withEquals(token.MINUS, token.MINUSASSIGN)
 	case '/':
-		tok = token.Token{Type: token.SLASH, Literal: string(lex.ch)}
+		tok = lex.withEquals(token.SLASH, token.SLASHASSIGN)
 	case '*':
-		tok = token.Token{Type: token.ASTERISK, Literal: string(lex.ch)}
+		if lex.peekAtNextChar() == '*' {
+			lex.readChar()
+			tok = token.Token{Type: token.POWER, Literal: "**"}
+		} else {
+			tok = lex.withEquals(token.ASTERISK, token.ASTERISKASSIGN)
+		}
+	case '%':
+		tok = lex.withEquals(token.PERCENT, token.PERCENTASSIGN)
+	case '^':
+		tok = token.Token{Type: token.BITXOR, Literal: string(lex.ch)}
+	case '~':
+		tok = token.Token{Type: token.TILDE, Literal: string(lex.ch)}
+	case '&':
+		tok = lex.doubled(token.BITAND, token.AND)
+	case '|':
+		tok = lex.doubled(token.BITOR, token.OR)
 	case '<':
-		tok = token.Token{Type: token.LESSERTHAN, Literal: string(lex.ch)}
+		if lex.peekAtNextChar() == '<' {
+			tok = lex.doubled(token.LESSERTHAN, token.SHIFTLEFT)
+		} else {
+			tok = lex.withEquals(token.LESSERTHAN, token.LESSEQUAL)
+		}
 	case '>':
-		tok = token.Token{Type: token.GREATERTHAN, Literal: string(lex.ch)}
+		if lex.peekAtNextChar() == '>' {
+			tok = lex.doubled(token.GREATERTHAN, token.SHIFTRIGHT)
+		} else {
+			tok = lex.withEquals(token.GREATERTHAN, token.GREATEREQUAL)
+		}
+	case '"':
+		if literal, ok := lex.readString(); ok {
+			tok = token.Token{Type: token.STRING, Literal: literal}
+		} else {
+			tok = token.Token{Type: token.ILLEGAL, Literal: literal}
+		}
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
+		return lex.locate(tok, start)
 	default:
 		if isLetter(lex.ch) {
 			tok.Literal = lex.readIdentifier()
 			tok.Type = token.LookForIdentifier(tok.Literal)
-			return tok
-		} else if isNumber(lex.ch) {
-			tok.Literal = lex.readNumber()
-			tok.Type = token.INT
-			return tok
+			return lex.locate(tok, start)
+		} else if isDigit(lex.ch) {
+			tok.Literal, tok.Type = lex.readNumber()
+			return lex.locate(tok, start)
 		} else {
 			tok = token.Token{Type: token.ILLEGAL, Literal: string(lex.ch)}
 		}
 	}
 
 	lex.readChar()
-	return tok
+	return lex.locate(tok, start)
+}
+
+// withEquals returns a compound token when the current character is followed
+// by '=', consuming both, and a single-character token otherwise.
+func (lex *Lexer) withEquals(single, compound token.TokenType) token.Token {
+	if lex.peekAtNextChar() == '=' {
+		currentChar := lex.ch
+		lex.readChar()
+		return token.Token{Type: compound, Literal: string(currentChar) + string(lex.ch)}
+	}
+	return token.Token{Type: single, Literal: string(lex.ch)}
+}
+
+// doubled returns a double token when the current character is repeated, as
+// in && or <<, consuming both, and a single-character token otherwise.
+func (lex *Lexer) doubled(single, double token.TokenType) token.Token {
+	if lex.peekAtNextChar() == lex.ch {
+		currentChar := lex.ch
+		lex.readChar()
+		return token.Token{Type: double, Literal: string(currentChar) + string(lex.ch)}
+	}
+	return token.Token{Type: single, Literal: string(lex.ch)}
+}
+
+// isLetter reports whether ch can start an identifier, which is any Unicode
+// letter or an underscore.
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func isLetter(ch byte) bool {
-	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
+// isIdentifierPart reports whether ch can continue an identifier. Besides
+// letters this takes digits and combining marks, the vowel signs and
+// viramas that Devanagari words are written with.
+func isIdentifierPart(ch rune) bool {
+	return isLetter(ch) || unicode.IsDigit(ch) || unicode.IsMark(ch)
 }
 
 func (lex *Lexer) readIdentifier() string {
 	position := lex.position
 
-	for isLetter(lex.ch) {
+	for isIdentifierPart(lex.ch) {
 		lex.readChar()
 	}
 
 	return lex.input[position:lex.position]
 }
 
-func isNumber(ch byte) bool {
-	return '0' <= ch && ch <= '9'
+// isDigit reports whether ch is a decimal digit in ASCII or Devanagari.
+func isDigit(ch rune) bool {
+	return '0' <= ch && ch <= '9' || '०' <= ch && ch <= '९'
 }
 
-func (lex *Lexer) readNumber() string {
+// readNumber reads an integer or a float such as 3.14, .5 or 2.5E+3, with
+// optional _ separators. Integers may also have a 0x, 0o or 0b prefix. A dot
+// only starts a fraction when a digit follows it, so 0..10 stays a range.
+// Malformed digits are left for the parser to report.
+func (lex *Lexer) readNumber() (string, token.TokenType) {
 	position := lex.position
+	tokenType := token.TokenType(token.INT)
 
-	for isNumber(lex.ch) {
+	if lex.ch == '0' && strings.ContainsRune("xXoObB", lex.peekAtNextChar()) {
 		lex.readChar()
+		lex.readChar()
+		for isHexDigit(lex.ch) || lex.ch == '_' {
+			lex.readChar()
+		}
+		return lex.input[position:lex.position], tokenType
 	}
 
-	return lex.input[position:lex.position]
+	lex.readDigits()
+
+	if lex.ch == '.' && isDigit(lex.peekAtNextChar()) {
+		tokenType = token.FLOAT
+		lex.readChar()
+		lex.readDigits()
+	}
+
+	if lex.ch == 'e' || lex.ch == 'E' {
+		next := lex.peekAtNextChar()
+		if next == '+' || next == '-' {
+			next = lex.peekAt(lex.readPosition + 1)
+		}
+		if isDigit(next) {
+			tokenType = token.FLOAT
+			lex.readChar()
+			if lex.ch == '+' || lex.ch == '-' {
+				lex.readChar()
+			}
+			lex.readDigits()
+		}
+	}
+
+	return lex.input[position:lex.position], tokenType
 }
 
-func (lex *Lexer) peekAtNextChar() byte {
-	if lex.readPosition >= len(lex.input) {
+func (lex *Lexer) readDigits() {
+	for isDigit(lex.ch) || lex.ch == '_' {
+		lex.readChar()
+	}
+}
+
+// readComment reads a // comment up to the end of the line or a /* */
+// comment, which may nest, and returns its text including the delimiters.
+// It reports false for a block comment left open at the end of the input.
+func (lex *Lexer) readComment() (string, bool) {
+	position := lex.position
+
+	if lex.peekAtNextChar() == '/' {
+		for lex.ch != '\n' && lex.ch != 0 {
+			lex.readChar()
+		}
+		return lex.input[position:lex.position], true
+	}
+
+	depth := 0
+	for lex.ch != 0 {
+		switch {
+		case lex.ch == '/' && lex.peekAtNextChar() == '*':
+			depth++
+			lex.readChar()
+		case lex.ch == '*' && lex.peekAtNextChar() == '/':
+			depth--
+			lex.readChar()
+		}
+		lex.readChar()
+
+		if depth == 0 {
+			return lex.input[position:lex.position], true
+		}
+	}
+
+	return lex.input[position:lex.position], false
+}
+
+// readString reads a double quoted string starting at the opening quote and
+// returns its value with escapes resolved, leaving lex.ch on the closing
+// quote. For a malformed string it returns the raw source text and false.
+func (lex *Lexer) readString() (string, bool) {
+	position := lex.position
+	var out strings.Builder
+	valid := true
+
+	for {
+		lex.readChar()
+
+		switch lex.ch {
+		case '"':
+			if !valid {
+				return lex.input[position : lex.position+1], false
+			}
+			return out.String(), true
+		case 0:
+			return lex.input[position:lex.position], false
+		case '\\':
+			lex.readChar()
+			switch lex.ch {
+			case 'n':
+				out.WriteByte('\n')
+			case 't':
+				out.WriteByte('\t')
+			case 'r':
+				out.WriteByte('\r')
+			case '"':
+				out.WriteByte('"')
+			case '\\':
+				out.WriteByte('\\')
+			case 'u':
+				r, ok := lex.readUnicodeEscape()
+				if !ok {
+					valid = false
+					continue
+				}
+				out.WriteRune(r)
+			case 0:
+				return lex.input[position:lex.position], false
+			default:
+				valid = false
+			}
+		default:
+			// copied as is, so that invalid UTF-8 survives unchanged
+			out.WriteString(lex.input[lex.position:lex.readPosition])
+		}
+	}
+}
+
+// readUnicodeEscape reads the {XXXX} part of a \u{XXXX} escape, leaving
+// lex.ch on the closing brace.
+func (lex *Lexer) readUnicodeEscape() (rune, bool) {
+	if lex.peekAtNextChar() != '{' {
+		return 0, false
+	}
+	lex.readChar()
+
+	position := lex.position + 1
+	for isHexDigit(lex.peekAtNextChar()) {
+		lex.readChar()
+	}
+	digits := lex.input[position:lex.readPosition]
+
+	if lex.peekAtNextChar() != '}' || len(digits) == 0 || len(digits) > 6 {
+		return 0, false
+	}
+	lex.readChar()
+
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(value)) {
+		return 0, false
+	}
+	return rune(value), true
+}
+
+func isHexDigit(ch rune) bool {
+	return '0' <= ch && ch <= '9' || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func (lex *Lexer) peekAtNextChar() rune {
+	return lex.peekAt(lex.readPosition)
+}
+
+// peekAt returns the rune starting at byte offset in the input.
+func (lex *Lexer) peekAt(offset int) rune {
+	if offset >= len(lex.input) {
 		return 0
 	}
-	return lex.input[lex.readPosition]
+	r, _ := utf8.DecodeRuneInString(lex.input[offset:])
+	return r
+}
+
+// KeepComments makes the lexer return comments as COMMENT tokens, for
+// tools that need them, instead of skipping them like whitespace.
+func (lex *Lexer) KeepComments(keep bool) {
+	lex.keepComments = keep
+}
+
+// Clone returns an independent copy of the lexer, for looking ahead
+// without consuming input.
+func (lex *Lexer) Clone() *Lexer {
+	clone := *lex
+	return &clone
 }
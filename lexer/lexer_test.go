@@ -14,12 +14,12 @@ func TestNextToken(T *testing.T) {
 		x + y;
 	}
 
-	!-/*5;
+	!-/ *5;
 	<>
 
 	yadi (5 < 10) {
 		firta satya;
-	} else {
+	} natra {
 		firta jhuth;
 	}
 
@@ -27,6 +27,12 @@ func TestNextToken(T *testing.T) {
 	10 != 69
 
 	manau result = add(a, b)
+	[1, 2][0:1];
+	jabasamma roka jari
+	pratyek i ma 0..10 0..=10
+	x += 1 -= *= /= %=
+	&& || ra wa
+	% ** <= >= & | ^ << >> ~ **=
 	`
 	tests := []struct {
 		expectedType    token.TokenType
@@ -63,7 +69,7 @@ func TestNextToken(T *testing.T) {
 		{token.SEMICOLON, ";"},
 		{token.RIGHTBRACES, "}"},
 
-		// !-/*5;
+		// !-/ *5;
 		{token.BANG, "!"},
 		{token.MINUS, "-"},
 		{token.SLASH, "/"},
@@ -86,7 +92,7 @@ func TestNextToken(T *testing.T) {
 		{token.TRUE, "satya"},
 		{token.SEMICOLON, ";"},
 		{token.RIGHTBRACES, "}"},
-		{token.ELSE, "else"},
+		{token.ELSE, "natra"},
 		{token.LEFTBRACES, "{"},
 		{token.RETURN, "firta"},
 		{token.FALSE, "jhuth"},
@@ -114,6 +120,64 @@ func TestNextToken(T *testing.T) {
 		{token.COMMA, ","},
 		{token.IDENTIFIER, "b"},
 		{token.RIGHTPARENTHESIS, ")"},
+
+		// [1, 2][0:1];
+		{token.LEFTBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RIGHTBRACKET, "]"},
+		{token.LEFTBRACKET, "["},
+		{token.INT, "0"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.RIGHTBRACKET, "]"},
+		{token.SEMICOLON, ";"},
+
+		// jabasamma roka jari
+		{token.WHILE, "jabasamma"},
+		{token.BREAK, "roka"},
+		{token.CONTINUE, "jari"},
+
+		// pratyek i ma 0..10 0..=10
+		{token.FOR, "pratyek"},
+		{token.IDENTIFIER, "i"},
+		{token.IN, "ma"},
+		{token.INT, "0"},
+		{token.DOTDOT, ".."},
+		{token.INT, "10"},
+		{token.INT, "0"},
+		{token.DOTDOTEQ, "..="},
+		{token.INT, "10"},
+
+		// x += 1 -= *= /= %=
+		{token.IDENTIFIER, "x"},
+		{token.PLUSASSIGN, "+="},
+		{token.INT, "1"},
+		{token.MINUSASSIGN, "-="},
+		{token.ASTERISKASSIGN, "*="},
+		{token.SLASHASSIGN, "/="},
+		{token.PERCENTASSIGN, "%="},
+
+		// && || ra wa
+		{token.AND, "&&"},
+		{token.OR, "||"},
+		{token.AND, "ra"},
+		{token.OR, "wa"},
+
+		// % ** <= >= & | ^ << >> ~ **=
+		{token.PERCENT, "%"},
+		{token.POWER, "**"},
+		{token.LESSEQUAL, "<="},
+		{token.GREATEREQUAL, ">="},
+		{token.BITAND, "&"},
+		{token.BITOR, "|"},
+		{token.BITXOR, "^"},
+		{token.SHIFTLEFT, "<<"},
+		{token.SHIFTRIGHT, ">>"},
+		{token.TILDE, "~"},
+		{token.POWER, "**"},
+		{token.ASSIGN, "="},
 		{token.EOF, ""},
 	}
 
@@ -131,3 +195,318 @@ func TestNextToken(T *testing.T) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "manau x = 5;\n  x + satya"
+
+	tests := []struct {
+		expectedLiteral string
+		line, column    int
+		offset          int
+		endColumn       int
+	}{
+		{"manau", 1, 1, 0, 6},
+		{"x", 1, 7, 6, 8},
+		{"=", 1, 9, 8, 10},
+		{"5", 1, 11, 10, 12},
+		{";", 1, 12, 11, 13},
+		{"x", 2, 3, 15, 4},
+		{"+", 2, 5, 17, 6},
+		{"satya", 2, 7, 19, 12},
+		{"", 2, 12, 24, 12},
+	}
+
+	lex := NewFile("script.goru", input)
+
+	for i, tt := range tests {
+		tok := lex.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Pos.Filename != "script.goru" {
+			t.Errorf("tests[%d] - filename wrong. got=%q", i, tok.Pos.Filename)
+		}
+
+		if tok.Pos.Line != tt.line || tok.Pos.Column != tt.column || tok.Pos.Offset != tt.offset {
+			t.Errorf("tests[%d] - position wrong. expected=%d:%d (offset %d), got=%d:%d (offset %d)",
+				i, tt.line, tt.column, tt.offset, tok.Pos.Line, tok.Pos.Column, tok.Pos.Offset)
+		}
+
+		if tok.End.Column != tt.endColumn {
+			t.Errorf("tests[%d] - end column wrong. expected=%d, got=%d", i, tt.endColumn, tok.End.Column)
+		}
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"foobar"`, token.STRING, "foobar"},
+		{`"foo bar"`, token.STRING, "foo bar"},
+		{`""`, token.STRING, ""},
+		{`"line\nnext\ttab"`, token.STRING, "line\nnext\ttab"},
+		{`"say \"namaste\""`, token.STRING, `say "namaste"`},
+		{`"back\\slash"`, token.STRING, `back\slash`},
+		{`"\u{928}\u{92E}\u{938}\u{94D}\u{924}\u{947}"`, token.STRING, "नमस्ते"},
+		{`"unterminated`, token.ILLEGAL, `"unterminated`},
+		{`"bad \q escape"`, token.ILLEGAL, `"bad \q escape"`},
+		{`"bad \u{110000}"`, token.ILLEGAL, `"bad \u{110000}"`},
+		{`"bad \u{}"`, token.ILLEGAL, `"bad \u{}"`},
+	}
+
+	for i, tt := range tests {
+		tok := New(tt.input).NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Errorf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.Token
+	}{
+		{"42", []token.Token{{Type: token.INT, Literal: "42"}}},
+		{"3.14", []token.Token{{Type: token.FLOAT, Literal: "3.14"}}},
+		{".5", []token.Token{{Type: token.FLOAT, Literal: ".5"}}},
+		{"1e-9", []token.Token{{Type: token.FLOAT, Literal: "1e-9"}}},
+		{"2.5E+3", []token.Token{{Type: token.FLOAT, Literal: "2.5E+3"}}},
+		{"6e23", []token.Token{{Type: token.FLOAT, Literal: "6e23"}}},
+		{"0xDead_Beef", []token.Token{{Type: token.INT, Literal: "0xDead_Beef"}}},
+		{"0o755", []token.Token{{Type: token.INT, Literal: "0o755"}}},
+		{"0B1010", []token.Token{{Type: token.INT, Literal: "0B1010"}}},
+		{"1_000_000", []token.Token{{Type: token.INT, Literal: "1_000_000"}}},
+		{"1_000.5e1_0", []token.Token{{Type: token.FLOAT, Literal: "1_000.5e1_0"}}},
+		{"४२", []token.Token{{Type: token.INT, Literal: "४२"}}},
+		{"३.१४", []token.Token{{Type: token.FLOAT, Literal: "३.१४"}}},
+		{".५", []token.Token{{Type: token.FLOAT, Literal: ".५"}}},
+		{"0x1..0x10", []token.Token{
+			{Type: token.INT, Literal: "0x1"},
+			{Type: token.DOTDOT, Literal: ".."},
+			{Type: token.INT, Literal: "0x10"},
+		}},
+		{"0b12", []token.Token{{Type: token.INT, Literal: "0b12"}}},
+		{"0..10", []token.Token{
+			{Type: token.INT, Literal: "0"},
+			{Type: token.DOTDOT, Literal: ".."},
+			{Type: token.INT, Literal: "10"},
+		}},
+		{"1.5..=2.5", []token.Token{
+			{Type: token.FLOAT, Literal: "1.5"},
+			{Type: token.DOTDOTEQ, Literal: "..="},
+			{Type: token.FLOAT, Literal: "2.5"},
+		}},
+		{"2e", []token.Token{
+			{Type: token.INT, Literal: "2"},
+			{Type: token.IDENTIFIER, Literal: "e"},
+		}},
+		{"3e+x", []token.Token{
+			{Type: token.INT, Literal: "3"},
+			{Type: token.IDENTIFIER, Literal: "e"},
+			{Type: token.PLUS, Literal: "+"},
+			{Type: token.IDENTIFIER, Literal: "x"},
+		}},
+		{"1.", []token.Token{
+			{Type: token.INT, Literal: "1"},
+			{Type: token.ILLEGAL, Literal: "."},
+		}},
+	}
+
+	for i, tt := range tests {
+		lex := New(tt.input)
+
+		for j, expected := range tt.expected {
+			tok := lex.NextToken()
+			if tok.Type != expected.Type || tok.Literal != expected.Literal {
+				t.Errorf("tests[%d][%d] - expected=%s %q, got=%s %q",
+					i, j, expected.Type, expected.Literal, tok.Type, tok.Literal)
+			}
+		}
+
+		if tok := lex.NextToken(); tok.Type != token.EOF {
+			t.Errorf("tests[%d] - expected EOF, got=%s %q", i, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestUnicodeIdentifiersAndKeywords(t *testing.T) {
+	input := `मानौ नाम = "गुरु";
+कार्य यदि नत्र सत्य झूठ फिर्ता जबसम्म रोक जारी प्रत्येक मा र वा
+फ्याँक प्रयास समात अन्तमा
+naam_2 café x१ _छ`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "मानौ"},
+		{token.IDENTIFIER, "नाम"},
+		{token.ASSIGN, "="},
+		{token.STRING, "गुरु"},
+		{token.SEMICOLON, ";"},
+		{token.FUNCTION, "कार्य"},
+		{token.IF, "यदि"},
+		{token.ELSE, "नत्र"},
+		{token.TRUE, "सत्य"},
+		{token.FALSE, "झूठ"},
+		{token.RETURN, "फिर्ता"},
+		{token.WHILE, "जबसम्म"},
+		{token.BREAK, "रोक"},
+		{token.CONTINUE, "जारी"},
+		{token.FOR, "प्रत्येक"},
+		{token.IN, "मा"},
+		{token.AND, "र"},
+		{token.OR, "वा"},
+		{token.THROW, "फ्याँक"},
+		{token.TRY, "प्रयास"},
+		{token.CATCH, "समात"},
+		{token.FINALLY, "अन्तमा"},
+		{token.IDENTIFIER, "naam_2"},
+		{token.IDENTIFIER, "café"},
+		{token.IDENTIFIER, "x१"},
+		{token.IDENTIFIER, "_छ"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+
+	for i, tt := range tests {
+		tok := lex.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q (%q)", i, tt.expectedType, tok.Type, tok.Literal)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnicodeColumnsCountRunes(t *testing.T) {
+	lex := New("मानौ नाम = ५ + €")
+
+	tests := []struct {
+		literal   string
+		column    int
+		offset    int
+		endColumn int
+	}{
+		{"मानौ", 1, 0, 5},
+		{"नाम", 6, 13, 9},
+		{"=", 10, 23, 11},
+		{"५", 12, 25, 13},
+		{"+", 14, 29, 15},
+		{"€", 16, 31, 17},
+	}
+
+	for i, tt := range tests {
+		tok := lex.NextToken()
+
+		if tok.Literal != tt.literal {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.literal, tok.Literal)
+		}
+
+		if tok.Pos.Column != tt.column || tok.Pos.Offset != tt.offset || tok.End.Column != tt.endColumn {
+			t.Errorf("tests[%d] - position wrong. expected=%d (offset %d) to %d, got=%d (offset %d) to %d",
+				i, tt.column, tt.offset, tt.endColumn, tok.Pos.Column, tok.Pos.Offset, tok.End.Column)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading comment
+manau x = 1; // trailing
+/* block
+   comment */ x / 2
+/* outer /* nested */ still outer */ x
+x /* between */ + 1 //`
+
+	expected := []token.Token{
+		{Type: token.LET, Literal: "manau"},
+		{Type: token.IDENTIFIER, Literal: "x"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.INT, Literal: "1"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.IDENTIFIER, Literal: "x"},
+		{Type: token.SLASH, Literal: "/"},
+		{Type: token.INT, Literal: "2"},
+		{Type: token.IDENTIFIER, Literal: "x"},
+		{Type: token.IDENTIFIER, Literal: "x"},
+		{Type: token.PLUS, Literal: "+"},
+		{Type: token.INT, Literal: "1"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	lex := New(input)
+	for i, tt := range expected {
+		tok := lex.NextToken()
+		if tok.Type != tt.Type || tok.Literal != tt.Literal {
+			t.Fatalf("tests[%d] - expected=%s %q, got=%s %q", i, tt.Type, tt.Literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestKeepComments(t *testing.T) {
+	input := "// doc\nmanau /* a /* b */ c */ x\n/* open"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		line, column    int
+	}{
+		{token.COMMENT, "// doc", 1, 1},
+		{token.LET, "manau", 2, 1},
+		{token.COMMENT, "/* a /* b */ c */", 2, 7},
+		{token.IDENTIFIER, "x", 2, 25},
+		{token.ILLEGAL, "/* open", 3, 1},
+		{token.EOF, "", 3, 8},
+	}
+
+	lex := New(input)
+	lex.KeepComments(true)
+
+	for i, tt := range tests {
+		tok := lex.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected=%s %q, got=%s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+
+		if tok.Pos.Line != tt.line || tok.Pos.Column != tt.column {
+			t.Errorf("tests[%d] - position wrong. expected=%d:%d, got=%s", i, tt.line, tt.column, tok.Pos)
+		}
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	tok := New("/* a /* b */").NextToken()
+
+	if tok.Type != token.ILLEGAL || tok.Literal != "/* a /* b */" {
+		t.Errorf("expected ILLEGAL %q, got=%s %q", "/* a /* b */", tok.Type, tok.Literal)
+	}
+}
+
+func TestShebangLine(t *testing.T) {
+	lex := New("#!/usr/bin/env app\nmanau")
+
+	tok := lex.NextToken()
+	if tok.Type != token.LET {
+		t.Fatalf("tokentype wrong. expected=%q, got=%q", token.LET, tok.Type)
+	}
+
+	if tok.Pos.Line != 2 || tok.Pos.Column != 1 {
+		t.Errorf("position wrong. expected=2:1, got=%s", tok.Pos)
+	}
+}
@@ -1,54 +1,78 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"strings"
 
-	"github.com/guruorgoru/goru-verbal-interpreter/eval"
-	"github.com/guruorgoru/goru-verbal-interpreter/lexer"
 	"github.com/guruorgoru/goru-verbal-interpreter/object"
-	"github.com/guruorgoru/goru-verbal-interpreter/parser"
 )
 
-const (
-	EXIT   = "exit"
-	HELP   = "help"
-	PROMPT = ">> "
-)
+const usage = `Usage:
+  app                      start the interactive REPL
+  app run file.goru [args] run a script file
+  app -e 'expr' [args]     evaluate a one-line program
+  app < file.goru          run a program read from stdin
+
+Options:
+  -checked                 report int64 overflow as an error instead of
+                           switching to big integers
+
+Scripts see their arguments as the array "args".
+`
+
+// checkedArithmetic is the -checked flag, applied to every environment the
+// program runs in.
+var checkedArithmetic bool
 
 func main() {
-	reader := bufio.NewReader(os.Stdin)
-	env := object.NewEnvironment()
-	for {
-		fmt.Print(PROMPT)
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
-		switch input {
-		case EXIT:
-			fmt.Println("Exiting interpreter. Goodbye!")
-			os.Exit(0)
-		case HELP:
-			fmt.Println("Available commands:")
-			fmt.Println("  help       - Show this help message")
-			fmt.Println("  exit       - Exit the interpreter")
-		default:
-			l := lexer.New(input)
-			p := parser.New(l)
-
-			program := p.ParseProgram()
-			if len(p.Errors()) != 0 {
-				log.Println(p.Errors())
-				continue
-			}
-
-			evaluated := eval.Eval(program, env)
-			if evaluated != nil {
-				fmt.Println(evaluated.Inspect())
-			}
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	expr := flag.String("e", "", "evaluate `program` and print its result")
+	flag.BoolVar(&checkedArithmetic, "checked", false, "report int64 overflow as an error instead of switching to big integers")
+	flag.Parse()
+
+	os.Exit(run(*expr, flag.Args()))
+}
+
+// run picks the mode from the command line and returns the exit status.
+func run(expr string, args []string) int {
+	switch {
+	case expr != "":
+		return runSource("-e", expr, args, true)
+	case len(args) > 0 && args[0] == "run":
+		if len(args) < 2 {
+			flag.Usage()
+			return 2
+		}
+		return runFile(args[1], args[2:])
+	case len(args) > 0:
+		flag.Usage()
+		return 2
+	case !isTerminal(os.Stdin):
+		source, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
 		}
+		return runSource("<stdin>", string(source), nil, false)
+	default:
+		startREPL()
+		return 0
+	}
+}
+
+// newEnvironment creates a top level environment set up from the flags.
+func newEnvironment() *object.Environment {
+	env := object.NewEnvironment()
+	env.SetCheckedArithmetic(checkedArithmetic)
+	return env
+}
 
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
 	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
@@ -1,16 +1,64 @@
 package object
 
+import "sort"
+
+// NewEnvironment creates the outermost environment of an evaluation.
+// Separate evaluations, such as two goroutines running their own programs,
+// each start from their own.
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s}
+	return &Environment{store: s, outer: nil, run: &evaluation{}}
+}
+
+// NewEnclosedEnvironment creates an environment whose lookups fall back to
+// outer, used for function calls so closures see their defining scope.
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	s := make(map[string]Object)
+	return &Environment{store: s, outer: outer, run: outer.run}
 }
 
 type Environment struct {
 	store map[string]Object
+	outer *Environment
+	run   *evaluation // shared with every environment enclosed in this one
+}
+
+// evaluation is the state of a running program beyond its bindings.
+type evaluation struct {
+	calls   int  // function calls in progress
+	checked bool // int64 overflow is an error rather than a BigInt
+}
+
+// SetCheckedArithmetic makes integer arithmetic that overflows int64 an
+// error instead of promoting the result to a BigInt, in e and every
+// environment enclosed in it.
+func (e *Environment) SetCheckedArithmetic(checked bool) {
+	e.run.checked = checked
+}
+
+func (e *Environment) CheckedArithmetic() bool {
+	return e.run.checked
+}
+
+// EnterCall counts a function call starting, unless max calls are already
+// in progress, in which case it reports false. LeaveCall counts one ending.
+func (e *Environment) EnterCall(max int) bool {
+	if e.run.calls >= max {
+		return false
+	}
+	e.run.calls++
+	return true
+}
+
+func (e *Environment) LeaveCall() {
+	e.run.calls--
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
 	obj, ok := e.store[name]
+	if !ok && e.outer != nil {
+		obj, ok = e.outer.Get(name)
+	}
 	return obj, ok
 }
 
@@ -18,3 +66,34 @@ func (e *Environment) Set(name string, val Object) Object {
 	e.store[name] = val
 	return val
 }
+
+// Assign updates the binding of name in the nearest environment that has
+// one, reporting false when name is not bound anywhere.
+func (e *Environment) Assign(name string, val Object) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns every name visible from this environment, including those
+// bound in outer environments, sorted.
+func (e *Environment) Names() []string {
+	seen := map[string]bool{}
+	names := []string{}
+
+	for env := e; env != nil; env = env.outer {
+		for name := range env.store {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -1,15 +1,36 @@
 package object
 
-import "fmt"
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/ast"
+	"github.com/guruorgoru/goru-verbal-interpreter/token"
+)
 
 type ObjectType string
 
 const (
 	INTEGER_OBJ         = "INTEGER"
+	BIGINT_OBJ          = "BIGINT"
+	FLOAT_OBJ           = "FLOAT"
+	STRING_OBJ          = "STRING"
 	BOOLEAN_OBJ         = "BOOLEAN"
 	NULL_OBJ            = "NULL"
 	RETURN_VALUE_OBJECT = "RETURN_VALUE"
 	ERROR_OBJ           = "ERROR"
+	FUNCTION_OBJ        = "FUNCTION"
+	ARRAY_OBJ           = "ARRAY"
+	HASH_OBJ            = "HASH"
+	BUILTIN_OBJ         = "BUILTIN"
+	BREAK_OBJ           = "BREAK"
+	CONTINUE_OBJ        = "CONTINUE"
+	RANGE_OBJ           = "RANGE"
 )
 
 type Object interface {
@@ -17,6 +38,19 @@ type Object interface {
 	Inspect() string
 }
 
+// HashKey identifies a hashable value, equal values give equal keys.
+
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Hashable is implemented by objects usable as hash keys.
+
+type Hashable interface {
+	HashKey() HashKey
+}
+
 // INTEGER
 
 type Integer struct {
@@ -31,10 +65,106 @@ func (i *Integer) Type() ObjectType {
 	return INTEGER_OBJ
 }
 
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
 // INTEGER END
 
 // -------------- //
 
+// BIGINT
+
+// BigInt holds integers that do not fit in an Integer. The evaluator only
+// creates one when the value is out of int64 range, so equal values never
+// show up as both types.
+
+type BigInt struct {
+	Value *big.Int
+}
+
+func (b *BigInt) Inspect() string {
+	return b.Value.String()
+}
+
+func (b *BigInt) Type() ObjectType {
+	return BIGINT_OBJ
+}
+
+func (b *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(b.Value.String()))
+
+	return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+
+// BIGINT END
+
+// -------------- //
+
+// FLOAT
+
+type Float struct {
+	Value float64
+}
+
+// Inspect prints the shortest text that reads back as the same float, and
+// always with a dot or exponent so it does not read back as an integer.
+func (f *Float) Inspect() string {
+	switch {
+	case math.IsNaN(f.Value):
+		return "NaN"
+	case math.IsInf(f.Value, 1):
+		return "+Inf"
+	case math.IsInf(f.Value, -1):
+		return "-Inf"
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f.Value); abs != 0 && (abs < 1e-4 || abs >= 1e16) {
+		format = 'e'
+	}
+
+	text := strconv.FormatFloat(f.Value, format, -1, 64)
+	if !strings.ContainsAny(text, ".e") {
+		text += ".0"
+	}
+	return text
+}
+
+func (f *Float) Type() ObjectType {
+	return FLOAT_OBJ
+}
+
+// FLOAT END
+
+// -------------- //
+
+// STRING
+
+type String struct {
+	Value string
+}
+
+func (s *String) Inspect() string {
+	return s.Value
+}
+
+func (s *String) Type() ObjectType {
+	return STRING_OBJ
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+// STRING END
+
+// -------------- //
+
 // BOOLEAN
 
 type Boolean struct {
@@ -49,6 +179,15 @@ func (b *Boolean) Type() ObjectType {
 	return BOOLEAN_OBJ
 }
 
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+
+	return HashKey{Type: b.Type(), Value: value}
+}
+
 // BOOLEAN END
 
 // -------------- //
@@ -92,14 +231,229 @@ func (rb *ReturnValue) Inspect() string {
 
 type Error struct {
 	Message string
+	Pos     token.Position // where the error was raised, if known
+	End     token.Position // just past the node that raised it
+	Stack   []Frame        // the calls it unwound through, innermost first
+	Thrown  Object         // the value thrown with fyak, nil for errors raised by the interpreter
+}
+
+// Frame is a function call that was in progress when an error was raised.
+type Frame struct {
+	Function string         // what the function was called as
+	Pos      token.Position // where it was called
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 
 func (e *Error) Inspect() string {
+	if e.Pos.IsValid() {
+		return "ERROR: " + e.Pos.String() + ": " + e.Message
+	}
 	return "ERROR: " + e.Message
 }
 
 // ERROR END
 
 // ---------- //
+
+// FUNCTION
+
+type Function struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+
+func (f *Function) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range f.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("karya(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(f.Body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
+
+// FUNCTION END
+
+// ---------- //
+
+// ARRAY
+
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+
+func (a *Array) Inspect() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, e := range a.Elements {
+		elements = append(elements, e.Inspect())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// ARRAY END
+
+// ---------- //
+
+// HASH
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash maps hashable keys to values and remembers the order keys were
+// first inserted in, which Inspect and iteration follow.
+
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	keys  []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+
+func (h *Hash) Get(key HashKey) (HashPair, bool) {
+	pair, ok := h.Pairs[key]
+	return pair, ok
+}
+
+// Set stores pair under key, keeping the original position of a key that
+// is already present.
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	if _, ok := h.Pairs[key]; !ok {
+		h.keys = append(h.keys, key)
+	}
+	h.Pairs[key] = pair
+}
+
+func (h *Hash) Len() int {
+	return len(h.keys)
+}
+
+// OrderedPairs returns the pairs in insertion order.
+func (h *Hash) OrderedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.keys))
+	for _, key := range h.keys {
+		pairs = append(pairs, h.Pairs[key])
+	}
+	return pairs
+}
+
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, pair := range h.OrderedPairs() {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// HASH END
+
+// ---------- //
+
+// BUILTIN
+
+// BuiltinFunction is a function implemented in Go and callable from scripts.
+type BuiltinFunction func(args ...Object) Object
+
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+
+func (b *Builtin) Inspect() string {
+	return "builtin " + b.Name
+}
+
+// BUILTIN END
+
+// ---------- //
+
+// BREAK / CONTINUE
+
+// Break and Continue unwind the statements of a loop body up to the loop,
+// the same way ReturnValue unwinds a function body.
+
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "roka" }
+
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "jari" }
+
+// BREAK / CONTINUE END
+
+// ---------- //
+
+// RANGE
+
+// Range is the integers from Start up to End, including End only when
+// Inclusive is set. Loops step through it without building an array.
+
+type Range struct {
+	Start     int64
+	End       int64
+	Inclusive bool
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+
+func (r *Range) Inspect() string {
+	if r.Inclusive {
+		return fmt.Sprintf("%d..=%d", r.Start, r.End)
+	}
+	return fmt.Sprintf("%d..%d", r.Start, r.End)
+}
+
+// Len returns how many integers the range holds. It is a big.Int because
+// MinInt64..=MaxInt64 holds 2^64 of them, more than any int64 can count.
+func (r *Range) Len() *big.Int {
+	if r.End < r.Start || r.End == r.Start && !r.Inclusive {
+		return new(big.Int)
+	}
+
+	n := new(big.Int).Sub(big.NewInt(r.End), big.NewInt(r.Start))
+	if r.Inclusive {
+		n.Add(n, big.NewInt(1))
+	}
+	return n
+}
+
+// RANGE END
+
+// ---------- //
@@ -0,0 +1,15 @@
+package parser
+
+import "github.com/guruorgoru/goru-verbal-interpreter/token"
+
+// Error is a syntax error found by the parser.
+type Error struct {
+	Pos      token.Position
+	Expected string      // what the parser was looking for, empty when it was not after anything in particular
+	Got      token.Token // the token it found instead
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + e.Message
+}
@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/guruorgoru/goru-verbal-interpreter/ast"
 	"github.com/guruorgoru/goru-verbal-interpreter/lexer"
@@ -17,39 +20,91 @@ type (
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN      // x = y or x += y
+	RANGE       // a..b
+	OR          // || or wa
+	AND         // && or ra
 	EQUALS      // ==
 	LESSGREATER // > or <
+	BITOR       // |
+	BITXOR      // ^
+	BITAND      // &
+	SHIFT       // << or >>
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
+	POWER       // **
 	CALL        // myFunction(X)
+	INDEX       // array[index]
 )
 
 var precedences = map[token.TokenType]int{
+	token.ASSIGN:          ASSIGN,
+	token.PLUSASSIGN:      ASSIGN,
+	token.MINUSASSIGN:     ASSIGN,
+	token.ASTERISKASSIGN:  ASSIGN,
+	token.SLASHASSIGN:     ASSIGN,
+	token.PERCENTASSIGN:   ASSIGN,
+	token.DOTDOT:          RANGE,
+	token.DOTDOTEQ:        RANGE,
+	token.OR:              OR,
+	token.AND:             AND,
 	token.EQUALS:          EQUALS,
 	token.NOTEQUALS:       EQUALS,
 	token.LESSERTHAN:      LESSGREATER,
 	token.GREATERTHAN:     LESSGREATER,
+	token.LESSEQUAL:       LESSGREATER,
+	token.GREATEREQUAL:    LESSGREATER,
+	token.BITOR:           BITOR,
+	token.BITXOR:          BITXOR,
+	token.BITAND:          BITAND,
+	token.SHIFTLEFT:       SHIFT,
+	token.SHIFTRIGHT:      SHIFT,
 	token.PLUS:            SUM,
 	token.MINUS:           SUM,
 	token.SLASH:           PRODUCT,
 	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.POWER:           POWER,
 	token.LEFTPARENTHESIS: CALL,
+	token.LEFTBRACKET:     INDEX,
 }
 
 type Parser struct {
 	lexer            *lexer.Lexer
 	currentToken     token.Token
 	nextToken        token.Token
-	errors           []string
+	errors           []*Error
+	incomplete       bool
+	panicking        bool // an error was found and the statement has not been recovered from yet
+	loopDepth        int  // loops enclosing the current statement, reset inside functions
+	braceDepth       int  // '{' minus '}' read up to and including currentToken
 	prefixParseFuncs map[token.TokenType]prefixParseFunc
 	infixParseFuncs  map[token.TokenType]infixParseFunc
+
+	// the token before currentToken, the comments read before currentToken
+	// and nextToken, and every comment group with the statement it documents
+	previousToken   token.Token
+	pushedBack      []token.Token // tokens to read again before asking the lexer
+	currentComments *ast.CommentGroup
+	nextComments    *ast.CommentGroup
+	comments        []*ast.CommentGroup
+	docs            map[ast.Statement]*ast.CommentGroup
 }
 
-func (p *Parser) Errors() []string {
+// Errors returns the syntax errors in the order they were found, leaving
+// out errors that only follow from an earlier one.
+func (p *Parser) Errors() []*Error {
 	return p.errors
 }
 
+// Incomplete reports whether parsing failed because the input ended in the
+// middle of a construct, like an unclosed block or a dangling operator, so
+// that more input could complete it.
+func (p *Parser) Incomplete() bool {
+	return p.incomplete
+}
+
 func (p *Parser) registerPrefix(token token.TokenType, prefixFunc prefixParseFunc) {
 	p.prefixParseFuncs[token] = prefixFunc
 }
@@ -59,19 +114,25 @@ func (p *Parser) registerInfix(token token.TokenType, infixFunc infixParseFunc)
 }
 
 func New(lexer *lexer.Lexer) *Parser {
-	p := &Parser{lexer: lexer, errors: []string{}}
+	p := &Parser{lexer: lexer, errors: []*Error{}}
 	p.readNextToken()
 	p.readNextToken()
 
 	p.prefixParseFuncs = make(map[token.TokenType]prefixParseFunc)
 	p.registerPrefix(token.IDENTIFIER, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LEFTPARENTHESIS, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.LEFTBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LEFTBRACES, p.parseHashLiteral)
 
 	p.infixParseFuncs = make(map[token.TokenType]infixParseFunc)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -81,8 +142,28 @@ func New(lexer *lexer.Lexer) *Parser {
 	p.registerInfix(token.EQUALS, p.parseInfixExpression)
 	p.registerInfix(token.NOTEQUALS, p.parseInfixExpression)
 	p.registerInfix(token.LESSERTHAN, p.parseInfixExpression)
-
 	p.registerInfix(token.GREATERTHAN, p.parseInfixExpression)
+	p.registerInfix(token.LESSEQUAL, p.parseInfixExpression)
+	p.registerInfix(token.GREATEREQUAL, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.BITOR, p.parseInfixExpression)
+	p.registerInfix(token.BITXOR, p.parseInfixExpression)
+	p.registerInfix(token.BITAND, p.parseInfixExpression)
+	p.registerInfix(token.SHIFTLEFT, p.parseInfixExpression)
+	p.registerInfix(token.SHIFTRIGHT, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parsePowerExpression)
+	p.registerInfix(token.LEFTPARENTHESIS, p.parseCallExpression)
+	p.registerInfix(token.LEFTBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUSASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUSASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISKASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASHASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PERCENTASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.DOTDOT, p.parseInfixExpression)
+	p.registerInfix(token.DOTDOTEQ, p.parseInfixExpression)
 
 	return p
 }
@@ -118,9 +199,80 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+// parsePowerExpression parses the right operand one level lower so that
+// 2 ** 3 ** 2 groups as 2 ** (3 ** 2).
+func (p *Parser) parsePowerExpression(left ast.Expression) ast.Expression {
+	expression := &ast.InfixExpression{
+		Token:    p.currentToken,
+		Operator: p.currentToken.Literal,
+		Left:     left,
+	}
+
+	p.readNextToken()
+	expression.Right = p.parseExpression(POWER - 1)
+
+	return expression
+}
+
 func (p *Parser) readNextToken() {
+	p.previousToken = p.currentToken
 	p.currentToken = p.nextToken
+	p.currentComments = p.nextComments
+	p.nextComments = nil
+	p.braceDepth += braceChange(p.currentToken)
+
+	if n := len(p.pushedBack); n > 0 {
+		p.nextToken = p.pushedBack[n-1]
+		p.pushedBack = p.pushedBack[:n-1]
+		return
+	}
+
 	p.nextToken = p.lexer.NextToken()
+
+	for p.nextToken.Type == token.COMMENT {
+		if p.nextComments == nil {
+			p.nextComments = &ast.CommentGroup{}
+			p.comments = append(p.comments, p.nextComments)
+		}
+		p.nextComments.List = append(p.nextComments.List, &ast.Comment{Token: p.nextToken})
+		p.nextToken = p.lexer.NextToken()
+	}
+}
+
+// skipSemicolon steps onto the optional ';' that ends a statement. After an
+// error it is left alone, as synchronize may still need the tokens before it.
+func (p *Parser) skipSemicolon() {
+	if p.nextToken.Type == token.SEMICOLON && !p.panicking {
+		p.readNextToken()
+	}
+}
+
+// backUp steps back one token, undoing the last readNextToken.
+func (p *Parser) backUp() {
+	p.braceDepth -= braceChange(p.currentToken)
+	p.pushedBack = append(p.pushedBack, p.nextToken)
+	p.nextToken = p.currentToken
+	p.currentToken = p.previousToken
+}
+
+func braceChange(tok token.Token) int {
+	switch tok.Type {
+	case token.LEFTBRACES:
+		return 1
+	case token.RIGHTBRACES:
+		return -1
+	}
+	return 0
+}
+
+// docComment returns the comments before the current token, unless they
+// start on the line of the token before them and so belong to that one.
+func (p *Parser) docComment() *ast.CommentGroup {
+	doc := p.currentComments
+	if doc == nil || (p.previousToken.End.IsValid() && doc.Pos().Line == p.previousToken.End.Line) {
+		return nil
+	}
+	return doc
 }
 
 func (p *Parser) nextPrecedence() int {
@@ -151,10 +303,35 @@ func (p *Parser) ParseProgram() *ast.Program {
 		p.readNextToken()
 	}
 
+	program.Comments = p.comments
+	for statement, doc := range p.docs {
+		program.SetDoc(statement, doc)
+	}
+
 	return program
 }
 
 func (p *Parser) ParseStatement() ast.Statement {
+	doc := p.docComment()
+	start := p.currentToken
+	outer := p.braceDepth - braceChange(start)
+	statement := p.parseStatement()
+
+	if p.panicking {
+		p.synchronize(start, outer)
+		return nil
+	}
+
+	if doc != nil && statement != nil {
+		if p.docs == nil {
+			p.docs = map[ast.Statement]*ast.CommentGroup{}
+		}
+		p.docs[statement] = doc
+	}
+	return statement
+}
+
+func (p *Parser) parseStatement() ast.Statement {
 	switch p.currentToken.Type {
 	case token.LET:
 		return p.parseLetStatement()
@@ -162,8 +339,25 @@ func (p *Parser) ParseStatement() ast.Statement {
 		return p.parseReturnStatement()
 	case token.IF:
 		return p.parseIfStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.TRY:
+		return p.parseTryStatement()
 	case token.SEMICOLON:
 		return nil
+	case token.LEFTBRACES:
+		if p.hashLiteralAhead() {
+			return p.parseExpressionStatement()
+		}
+		return p.parseBlockStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -185,9 +379,7 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	p.readNextToken()
 	stmt.Value = p.parseExpression(LOWEST)
 
-	if p.nextToken.Type == token.SEMICOLON {
-		p.readNextToken()
-	}
+	p.skipSemicolon()
 
 	return stmt
 }
@@ -195,16 +387,51 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	literal := &ast.IntegerLiteral{Token: p.currentToken}
 
-	value, err := strconv.ParseInt(p.currentToken.Literal, 0, 64)
+	value, err := strconv.ParseInt(numberText(p.currentToken.Literal), 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return p.parseBigIntegerLiteral()
+	}
 	if err != nil {
-		msg := "could not parse %q as integer"
-		p.errors = append(p.errors, fmt.Sprintf(msg, p.currentToken.Literal))
+		p.errorAt(p.currentToken, "could not parse %q as integer", p.currentToken.Literal)
 		return nil
 	}
 	literal.Value = value
 	return literal
 }
 
+func (p *Parser) parseBigIntegerLiteral() ast.Expression {
+	value, ok := new(big.Int).SetString(numberText(p.currentToken.Literal), 0)
+	if !ok {
+		p.errorAt(p.currentToken, "could not parse %q as integer", p.currentToken.Literal)
+		return nil
+	}
+	return &ast.BigIntegerLiteral{Token: p.currentToken, Value: value}
+}
+
+// numberText spells the Devanagari digits of a number literal in ASCII, so
+// that strconv can parse it.
+func numberText(literal string) string {
+	return strings.Map(func(r rune) rune {
+		if '०' <= r && r <= '९' {
+			return '0' + r - '०'
+		}
+		return r
+	}, literal)
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	value, err := strconv.ParseFloat(numberText(p.currentToken.Literal), 64)
+	if err != nil {
+		p.errorAt(p.currentToken, "could not parse %q as float", p.currentToken.Literal)
+		return nil
+	}
+	return &ast.FloatLiteral{Token: p.currentToken, Value: value}
+}
+
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.currentToken, Value: p.currentToken.Literal}
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	statement := &ast.ReturnStatement{Token: p.currentToken}
 
@@ -214,21 +441,17 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 		statement.ReturnValue = p.parseExpression(LOWEST)
 	}
 
-	if p.nextToken.Type == token.SEMICOLON {
-		p.readNextToken()
-	}
+	p.skipSemicolon()
 
 	return statement
 }
 
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
-	statement := &ast.ExpressionStatement{}
+	statement := &ast.ExpressionStatement{Token: p.currentToken}
 
 	statement.Expression = p.parseExpression(LOWEST)
 
-	if p.nextToken.Type == token.SEMICOLON {
-		p.readNextToken()
-	}
+	p.skipSemicolon()
 
 	return statement
 }
@@ -236,9 +459,27 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParseFuncs[p.currentToken.Type]
 
+	if prefix == nil && p.currentTokenIs(token.ILLEGAL) && strings.HasPrefix(p.currentToken.Literal, "/*") {
+		// more input could still close the comment
+		p.incomplete = len(p.errors) == 0
+		p.errorAt(p.currentToken, "unterminated comment")
+		return nil
+	}
+
+	if prefix == nil && p.currentTokenIs(token.ILLEGAL) && unterminatedString(p.currentToken.Literal) {
+		// strings may span lines, so more input could still close it
+		p.incomplete = len(p.errors) == 0
+		p.errorAt(p.currentToken, "unterminated string")
+		return nil
+	}
+
+	if prefix == nil && p.currentTokenIs(token.ILLEGAL) {
+		p.errorAt(p.currentToken, "illegal token %q", p.currentToken.Literal)
+		return nil
+	}
+
 	if prefix == nil {
-		msg := fmt.Sprintf("no prefix parse function for %v", p.currentToken.Type)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.currentToken, "no prefix parse function for %v", p.currentToken.Type)
 		return nil
 	}
 	leftExpression := prefix()
@@ -287,8 +528,97 @@ func (p *Parser) currentTokenIs(t token.TokenType) bool {
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	msg := "expected next token to be %s, got %s instead"
-	p.errors = append(p.errors, fmt.Sprintf(msg, t, p.nextToken.Type))
+	p.expectedAt(p.nextToken, string(t))
+}
+
+// expectedAt records that expected was wanted where tok was found.
+func (p *Parser) expectedAt(tok token.Token, expected string) {
+	p.addError(&Error{
+		Pos:      tok.Pos,
+		Expected: expected,
+		Got:      tok,
+		Message:  fmt.Sprintf("expected next token to be %s, got %s instead", expected, tok.Type),
+	})
+}
+
+// errorAt records an error about tok.
+func (p *Parser) errorAt(tok token.Token, format string, a ...any) {
+	p.addError(&Error{Pos: tok.Pos, Got: tok, Message: fmt.Sprintf(format, a...)})
+}
+
+// addError records err unless the parser is still recovering from an
+// earlier error, in which case err is most likely a consequence of it.
+func (p *Parser) addError(err *Error) {
+	if p.panicking {
+		return
+	}
+	for _, seen := range p.errors {
+		if seen.Pos == err.Pos && seen.Message == err.Message {
+			return
+		}
+	}
+
+	if len(p.errors) == 0 && err.Got.Type == token.EOF {
+		p.incomplete = true
+	}
+	p.errors = append(p.errors, err)
+	p.panicking = true
+}
+
+// synchronize skips the rest of a statement that began at start, inside
+// outer open blocks, and had an error, so parsing can carry on with the
+// next one. It stops after a ';', after a '}' that closes a block opened in
+// the statement, or before a '}' closing the enclosing block or a keyword
+// that starts a statement.
+func (p *Parser) synchronize(start token.Token, outer int) {
+	p.panicking = false
+
+	if p.currentToken.Pos != start.Pos {
+		if p.currentTokenIs(token.RIGHTBRACES) && p.braceDepth >= outer {
+			// the statement ended with one of its own blocks
+			return
+		}
+
+		switch p.currentToken.Type {
+		case token.RIGHTBRACES, token.LET, token.RETURN, token.WHILE, token.FOR, token.BREAK, token.CONTINUE, token.THROW, token.TRY:
+			// the statement ran into the end of its block or the start of
+			// the next statement, which still have to be parsed
+			p.backUp()
+			return
+		}
+	}
+
+	depth := 0
+	for {
+		switch p.currentToken.Type {
+		case token.LEFTBRACES:
+			depth++
+		case token.RIGHTBRACES:
+			depth--
+			if depth <= 0 {
+				return
+			}
+		case token.SEMICOLON:
+			if depth == 0 {
+				return
+			}
+		}
+
+		switch p.nextToken.Type {
+		case token.EOF:
+			return
+		case token.RIGHTBRACES:
+			if depth == 0 {
+				return
+			}
+		case token.LET, token.RETURN, token.IF, token.WHILE, token.FOR, token.BREAK, token.CONTINUE, token.THROW, token.TRY:
+			if depth == 0 {
+				return
+			}
+		}
+
+		p.readNextToken()
+	}
 }
 
 func (p *Parser) parseIfStatement() *ast.IfStatement {
@@ -370,6 +700,10 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		}
 		p.readNextToken()
 	}
+	if p.currentTokenIs(token.EOF) {
+		p.errorAt(p.currentToken, "expected %s, got %s instead", token.RIGHTBRACES, token.EOF)
+	}
+	block.RightBrace = p.currentToken
 
 	return block
 }
@@ -387,8 +721,375 @@ func (p *Parser) parseBlockExpression() ast.Expression {
 		}
 		p.readNextToken()
 	}
+	if p.currentTokenIs(token.EOF) {
+		p.errorAt(p.currentToken, "expected %s, got %s instead", token.RIGHTBRACES, token.EOF)
+	}
+	block.RightBrace = p.currentToken
 
 	return block
 }
 
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	literal := &ast.FunctionLiteral{Token: p.currentToken}
+
+	if !p.expectNextToken(token.LEFTPARENTHESIS) {
+		return nil
+	}
+
+	literal.Parameters = p.parseFunctionParameters()
+
+	if !p.expectNextToken(token.LEFTBRACES) {
+		return nil
+	}
+
+	// roka and jari cannot reach a loop outside the function
+	loopDepth := p.loopDepth
+	p.loopDepth = 0
+	literal.Body = p.parseBlockStatement()
+	p.loopDepth = loopDepth
+
+	return literal
+}
+
+func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+	identifiers := []*ast.Identifier{}
+
+	if p.nextToken.Type == token.RIGHTPARENTHESIS {
+		p.readNextToken()
+		return identifiers
+	}
+
+	if !p.expectNextToken(token.IDENTIFIER) {
+		return nil
+	}
+	identifiers = append(identifiers, &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal})
+
+	for p.nextToken.Type == token.COMMA {
+		p.readNextToken()
+		if !p.expectNextToken(token.IDENTIFIER) {
+			return nil
+		}
+		identifiers = append(identifiers, &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal})
+	}
+
+	if !p.expectNextToken(token.RIGHTPARENTHESIS) {
+		return nil
+	}
+
+	return identifiers
+}
+
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	expression := &ast.CallExpression{Token: p.currentToken, Function: function}
+	expression.Arguments = p.parseExpressionList(token.RIGHTPARENTHESIS)
+	expression.RightParen = p.currentToken
+	return expression
+}
+
+// parseExpressionList parses comma separated expressions up to and
+// including the end token.
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
+
+	if p.nextToken.Type == end {
+		p.readNextToken()
+		return list
+	}
+
+	p.readNextToken()
+	list = append(list, p.parseExpression(LOWEST))
+
+	for p.nextToken.Type == token.COMMA {
+		p.readNextToken()
+		p.readNextToken()
+		list = append(list, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectNextToken(end) {
+		return nil
+	}
+
+	return list
+}
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.currentToken}
+	array.Elements = p.parseExpressionList(token.RIGHTBRACKET)
+	array.RightBracket = p.currentToken
+	return array
+}
+
+// parseIndexExpression parses both arr[i] and the arr[low:high] slice form.
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	start := p.currentToken
+
+	var index ast.Expression
+	if p.nextToken.Type != token.COLON {
+		p.readNextToken()
+		index = p.parseExpression(LOWEST)
+	}
+
+	if p.nextToken.Type != token.COLON {
+		if !p.expectNextToken(token.RIGHTBRACKET) {
+			return nil
+		}
+		return &ast.IndexExpression{Token: start, Left: left, Index: index, RightBracket: p.currentToken}
+	}
+
+	slice := &ast.SliceExpression{Token: start, Left: left, Low: index}
+	p.readNextToken()
+
+	if p.nextToken.Type != token.RIGHTBRACKET {
+		p.readNextToken()
+		slice.High = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectNextToken(token.RIGHTBRACKET) {
+		return nil
+	}
+	slice.RightBracket = p.currentToken
+
+	return slice
+}
+
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.currentToken, Pairs: []ast.HashPair{}}
+
+	for p.nextToken.Type != token.RIGHTBRACES {
+		p.readNextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectNextToken(token.COLON) {
+			return nil
+		}
+
+		p.readNextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if p.nextToken.Type != token.RIGHTBRACES && !p.expectNextToken(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectNextToken(token.RIGHTBRACES) {
+		return nil
+	}
+	hash.RightBrace = p.currentToken
+
+	return hash
+}
+
+// hashLiteralAhead reports whether the '{' starting a statement opens a hash
+// literal rather than a block. It scans ahead on a copy of the lexer: `{}`
+// and `{ <expression> :` are hashes, anything else is a block.
+func (p *Parser) hashLiteralAhead() bool {
+	switch p.nextToken.Type {
+	case token.RIGHTBRACES:
+		return true
+	case token.LET, token.RETURN, token.IF, token.WHILE, token.FOR, token.BREAK, token.CONTINUE:
+		return false
+	}
+
+	lookahead := p.lexer.Clone()
+	depth := 0
+
+	for tok := p.nextToken; tok.Type != token.EOF; tok = lookahead.NextToken() {
+		switch tok.Type {
+		case token.LEFTPARENTHESIS, token.LEFTBRACKET, token.LEFTBRACES:
+			depth++
+		case token.RIGHTPARENTHESIS, token.RIGHTBRACKET:
+			depth--
+		case token.RIGHTBRACES:
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case token.SEMICOLON:
+			if depth == 0 {
+				return false
+			}
+		case token.COLON:
+			if depth == 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{Token: p.currentToken, Target: target}
+
+	// a target that failed to parse may have nil parts, and its error has
+	// already been reported
+	if target == nil || p.panicking {
+		return nil
+	}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	default:
+		p.errorAt(expression.Token, "cannot assign to %s", target.String())
+		return nil
+	}
+
+	p.readNextToken()
+	// one below ASSIGN so that a[0] = b[0] = 1 groups to the right
+	expression.Value = p.parseExpression(ASSIGN - 1)
+
+	return expression
+}
+
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	statement := &ast.WhileStatement{Token: p.currentToken}
+
+	if !p.expectNextToken(token.LEFTPARENTHESIS) {
+		return nil
+	}
+
+	p.readNextToken()
+	statement.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectNextToken(token.RIGHTPARENTHESIS) {
+		return nil
+	}
+
+	if !p.expectNextToken(token.LEFTBRACES) {
+		return nil
+	}
+
+	p.loopDepth++
+	statement.Body = p.parseBlockStatement()
+	p.loopDepth--
+
+	return statement
+}
+
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	statement := &ast.ForStatement{Token: p.currentToken}
+
+	if !p.expectNextToken(token.IDENTIFIER) {
+		return nil
+	}
+	statement.Variable = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	if !p.expectNextToken(token.IN) {
+		return nil
+	}
+
+	p.readNextToken()
+	statement.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectNextToken(token.LEFTBRACES) {
+		return nil
+	}
+
+	p.loopDepth++
+	statement.Body = p.parseBlockStatement()
+	p.loopDepth--
+
+	return statement
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	statement := &ast.BreakStatement{Token: p.currentToken}
+
+	if p.loopDepth == 0 {
+		p.errorAt(p.currentToken, "%s outside of a loop", p.currentToken.Literal)
+	}
+
+	p.skipSemicolon()
+
+	return statement
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	statement := &ast.ContinueStatement{Token: p.currentToken}
+
+	if p.loopDepth == 0 {
+		p.errorAt(p.currentToken, "%s outside of a loop", p.currentToken.Literal)
+	}
+
+	p.skipSemicolon()
+
+	return statement
+}
+
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	statement := &ast.ThrowStatement{Token: p.currentToken}
+
+	p.readNextToken()
+	statement.Value = p.parseExpression(LOWEST)
+
+	p.skipSemicolon()
+
+	return statement
+}
+
+func (p *Parser) parseTryStatement() *ast.TryStatement {
+	statement := &ast.TryStatement{Token: p.currentToken}
+
+	if !p.expectNextToken(token.LEFTBRACES) {
+		return nil
+	}
+	statement.Body = p.parseBlockStatement()
+
+	if p.nextToken.Type == token.CATCH {
+		p.readNextToken()
+
+		if !p.expectNextToken(token.LEFTPARENTHESIS) {
+			return nil
+		}
+		if !p.expectNextToken(token.IDENTIFIER) {
+			return nil
+		}
+		statement.CatchParam = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+		if !p.expectNextToken(token.RIGHTPARENTHESIS) {
+			return nil
+		}
+		if !p.expectNextToken(token.LEFTBRACES) {
+			return nil
+		}
+		statement.Catch = p.parseBlockStatement()
+	}
+
+	if p.nextToken.Type == token.FINALLY {
+		p.readNextToken()
+
+		if !p.expectNextToken(token.LEFTBRACES) {
+			return nil
+		}
+		statement.Finally = p.parseBlockStatement()
+	}
+
+	if statement.Catch == nil && statement.Finally == nil {
+		p.expectedAt(p.nextToken, "samata or antama")
+		return nil
+	}
+
+	return statement
+}
+
+// unterminatedString reports whether literal, the text of an illegal token,
+// is a string that ran into the end of the input without a closing quote.
+func unterminatedString(literal string) bool {
+	if !strings.HasPrefix(literal, `"`) {
+		return false
+	}
+
+	for i := 1; i < len(literal); i++ {
+		switch literal[i] {
+		case '\\':
+			i++
+		case '"':
+			return false
+		}
+	}
+	return true
+}
+
 // Too long file, sorry :)
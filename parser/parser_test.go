@@ -2,10 +2,12 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/guruorgoru/goru-verbal-interpreter/ast"
 	"github.com/guruorgoru/goru-verbal-interpreter/lexer"
+	"github.com/guruorgoru/goru-verbal-interpreter/token"
 )
 
 func TestLetStatement(t *testing.T) {
@@ -104,6 +106,132 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	testIntegerLiteral(t, literal, 5)
 }
 
+func TestNumberLiteralSyntax(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xff", 255},
+		{"0XFF", 255},
+		{"0o17", 15},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0x_dead_beef", 0xdeadbeef},
+		{"४२", 42},
+		{"१_०००", 1000},
+		{"९८७६५४३२१०", 9876543210},
+	}
+
+	for _, tt := range tests {
+		program := parseProgram(t, tt.input)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %d. got=%d", tt.expected, literal.Value)
+		}
+
+		if literal.String() != tt.input {
+			t.Errorf("literal.String() not %q. got=%q", tt.input, literal.String())
+		}
+	}
+}
+
+func TestMalformedNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1__0", `1:1: could not parse "1__0" as integer`},
+		{"1_", `1:1: could not parse "1_" as integer`},
+		{"0b102", `1:1: could not parse "0b102" as integer`},
+		{"0x", `1:1: could not parse "0x" as integer`},
+		{"1_.5", `1:1: could not parse "1_.5" as float`},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected an error for %q", tt.input)
+		}
+
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0].Error())
+		}
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{".5", 0.5},
+		{"1e-9", 1e-9},
+		{"2.5E+3", 2500},
+		{"1_000.25", 1000.25},
+		{"३.५", 3.5},
+		{"१e३", 1000},
+	}
+
+	for _, tt := range tests {
+		program := parseProgram(t, tt.input)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+
+		if literal.String() != tt.input {
+			t.Errorf("literal.String() not %q. got=%q", tt.input, literal.String())
+		}
+	}
+}
+
+func TestBigIntegerLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775808", "9223372036854775808"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+	}
+
+	for _, tt := range tests {
+		program := parseProgram(t, tt.input)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.BigIntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.BigIntegerLiteral. got=%T", stmt.Expression)
+		}
+
+		if literal.Value.String() != tt.expected {
+			t.Errorf("literal.Value not %s. got=%s", tt.expected, literal.Value)
+		}
+	}
+
+	// the largest int64 still gives a plain IntegerLiteral
+	program := parseProgram(t, "9223372036854775807")
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	if _, ok := stmt.Expression.(*ast.IntegerLiteral); !ok {
+		t.Errorf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+	}
+}
+
 func TestParsingPrefixExpression(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -145,6 +273,15 @@ func TestParsingInfixExpression(t *testing.T) {
 		{"993322 - 123456;", 993322, "-", 123456},
 		{"50 * 2;", 50, "*", 2},
 		{"100 / 4;", 100, "/", 4},
+		{"7 % 3;", 7, "%", 3},
+		{"2 ** 8;", 2, "**", 8},
+		{"5 <= 6;", 5, "<=", 6},
+		{"5 >= 6;", 5, ">=", 6},
+		{"6 & 3;", 6, "&", 3},
+		{"6 | 3;", 6, "|", 3},
+		{"6 ^ 3;", 6, "^", 3},
+		{"1 << 4;", 1, "<<", 4},
+		{"16 >> 2;", 16, ">>", 2},
 	}
 
 	for _, tt := range infixTests {
@@ -221,6 +358,90 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"3 + 4 * 5 == 3 * 1 + 4 * 5",
 			"((3 + (4 * 5)) == ((3 * 1) + (4 * 5)))",
 		},
+		{
+			"a + add(b * c) + d",
+			"((a + add((b * c))) + d)",
+		},
+		{
+			"add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))",
+			"add(a, b, 1, (2 * 3), (4 + 5), add(6, (7 * 8)))",
+		},
+		{
+			"add(a + b + c * d / f + g)",
+			"add((((a + b) + ((c * d) / f)) + g))",
+		},
+		{
+			"a * [1, 2, 3, 4][b * c] * d",
+			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		},
+		{
+			"add(a * b[2], b[1], 2 * [1, 2][1])",
+			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		},
+		{
+			"0..n + 1",
+			"(0 .. (n + 1))",
+		},
+		{
+			"a..=b == c",
+			"(a ..= (b == c))",
+		},
+		{
+			"a[1:-1][:2][b:]",
+			"(((a[1:(-1)])[:2])[b:])",
+		},
+		{
+			"a || b && c",
+			"(a || (b && c))",
+		},
+		{
+			"a && b || c && d",
+			"((a && b) || (c && d))",
+		},
+		{
+			"a == 1 || b < 2 && !c",
+			"((a == 1) || ((b < 2) && (!c)))",
+		},
+		{
+			"x = a || b",
+			"(x = (a || b))",
+		},
+		{
+			"a ra b wa c",
+			"((a ra b) wa c)",
+		},
+		{
+			"2 ** 3 ** 2",
+			"(2 ** (3 ** 2))",
+		},
+		{
+			"-2 ** 2",
+			"(-(2 ** 2))",
+		},
+		{
+			"2 ** -1",
+			"(2 ** (-1))",
+		},
+		{
+			"a * b ** c % d",
+			"((a * (b ** c)) % d)",
+		},
+		{
+			"a | b ^ c & d << e + f",
+			"(a | (b ^ (c & (d << (e + f)))))",
+		},
+		{
+			"x & 1 == 0",
+			"((x & 1) == 0)",
+		},
+		{
+			"a <= b == b >= a",
+			"((a <= b) == (b >= a))",
+		},
+		{
+			"~a & ~b",
+			"((~a) & (~b))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -267,6 +488,745 @@ func TestBooleanExpression(t *testing.T) {
 	}
 }
 
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"namaste duniya";`
+
+	program := parseProgram(t, input)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+	}
+
+	if literal.Value != "namaste duniya" {
+		t.Errorf("literal.Value not %q. got=%q", "namaste duniya", literal.Value)
+	}
+}
+
+func TestParsingArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	program := parseProgram(t, input)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(array.Elements) != 3 {
+		t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
+	}
+
+	testIntegerLiteral(t, array.Elements[0], 1)
+	testInfixExpression(t, array.Elements[1], 2, "*", 2)
+	testInfixExpression(t, array.Elements[2], 3, "+", 3)
+}
+
+func TestParsingIndexExpressions(t *testing.T) {
+	input := "myArray[1 + 1]"
+
+	program := parseProgram(t, input)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.IndexExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, indexExp.Left, "myArray") {
+		return
+	}
+
+	testInfixExpression(t, indexExp.Index, 1, "+", 1)
+}
+
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input string
+		low   any
+		high  any
+	}{
+		{"arr[1:2]", 1, 2},
+		{"arr[:2]", nil, 2},
+		{"arr[1:]", 1, nil},
+		{"arr[:]", nil, nil},
+	}
+
+	for _, tt := range tests {
+		program := parseProgram(t, tt.input)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		slice, ok := stmt.Expression.(*ast.SliceExpression)
+		if !ok {
+			t.Fatalf("exp not *ast.SliceExpression. got=%T", stmt.Expression)
+		}
+
+		testIdentifier(t, slice.Left, "arr")
+
+		if tt.low == nil && slice.Low != nil {
+			t.Errorf("slice.Low not nil. got=%s", slice.Low)
+		} else if tt.low != nil {
+			testLiteralExpression(t, slice.Low, tt.low)
+		}
+
+		if tt.high == nil && slice.High != nil {
+			t.Errorf("slice.High not nil. got=%s", slice.High)
+		} else if tt.high != nil {
+			testLiteralExpression(t, slice.High, tt.high)
+		}
+	}
+}
+
+func TestParsingHashLiterals(t *testing.T) {
+	input := `manau h = {"one": 1, "two": 2, 3: satya};`
+
+	program := parseProgram(t, input)
+
+	stmt := program.Statements[0].(*ast.LetStatement)
+	hash, ok := stmt.Value.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Value)
+	}
+
+	expected := `{"one": 1, "two": 2, 3: satya}`
+	if hash.String() != expected {
+		t.Errorf("hash.String() wrong. expected=%q, got=%q", expected, hash.String())
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+
+	testIntegerLiteral(t, hash.Pairs[0].Value, 1)
+	testIntegerLiteral(t, hash.Pairs[1].Value, 2)
+}
+
+func TestParsingHashLiteralsWithExpressions(t *testing.T) {
+	input := `{"one": 0 + 1, "two": 10 - 8}`
+
+	program := parseProgram(t, input)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	testInfixExpression(t, hash.Pairs[0].Value, 0, "+", 1)
+	testInfixExpression(t, hash.Pairs[1].Value, 10, "-", 8)
+}
+
+func TestBlockOrHashStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{}", "*ast.ExpressionStatement"},
+		{`{"a": 1}`, "*ast.ExpressionStatement"},
+		{`{"a": 1}["a"]`, "*ast.ExpressionStatement"},
+		{"{ x }", "*ast.BlockStatement"},
+		{"{ x; y: 1 }", "*ast.BlockStatement"},
+		{"{ manau x = 1; }", "*ast.BlockStatement"},
+		{"{ a[1:2] }", "*ast.BlockStatement"},
+		{"{ f(x) }", "*ast.BlockStatement"},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input)
+		p := New(lex)
+		program := p.ParseProgram()
+
+		if len(program.Statements) == 0 {
+			t.Errorf("no statements parsed for %q, errors: %v", tt.input, p.Errors())
+			continue
+		}
+
+		if got := fmt.Sprintf("%T", program.Statements[0]); got != tt.expected {
+			t.Errorf("wrong statement for %q. expected=%s, got=%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestParsingIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`h["k"] = 1`, `((h["k"]) = 1)`},
+		{"a[0] = b[1] = 2 + 3", "((a[0]) = ((b[1]) = (2 + 3)))"},
+		{"x = 5", "(x = 5)"},
+		{"x = y = 1 + 2", "(x = (y = (1 + 2)))"},
+		{"x += 1 * 2", "(x += (1 * 2))"},
+		{`h["k"] -= 2`, `((h["k"]) -= 2)`},
+		{"a[0] *= b /= c %= 2", "((a[0]) *= (b /= (c %= 2)))"},
+	}
+
+	for _, tt := range tests {
+		program := parseProgram(t, tt.input)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidAssignmentTarget(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 = 1", "1:3: cannot assign to 5"},
+		{"f() += 1", "1:5: cannot assign to f()"},
+		{"(a + b) = 1", "1:9: cannot assign to (a + b)"},
+		// targets that did not parse are reported once, not formatted
+		{"-manau = 5", "1:2: no prefix parse function for LET"},
+		{"!roka += 1", "1:2: no prefix parse function for BREAK"},
+		{"x / ) = 1", "1:5: no prefix parse function for )"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 error for %q, got %v", tt.input, errors)
+		}
+
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0].Error())
+		}
+	}
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := `jabasamma (x < 10) { yadi (x == 5) { roka; } jari; }`
+
+	program := parseProgram(t, input)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T", program.Statements[0])
+	}
+
+	testInfixExpression(t, stmt.Condition, "x", "<", 10)
+
+	if len(stmt.Body.Statements) != 2 {
+		t.Fatalf("body has not 2 statements. got=%d", len(stmt.Body.Statements))
+	}
+
+	ifStmt, ok := stmt.Body.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("body.Statements[0] is not ast.IfStatement. got=%T", stmt.Body.Statements[0])
+	}
+
+	if _, ok := ifStmt.Consequence.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("consequence is not ast.BreakStatement. got=%T", ifStmt.Consequence.Statements[0])
+	}
+
+	if _, ok := stmt.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Errorf("body.Statements[1] is not ast.ContinueStatement. got=%T", stmt.Body.Statements[1])
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	input := `pratyek x ma 1..10 { yadi (x == 5) { roka; } x }`
+
+	program := parseProgram(t, input)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+	}
+
+	testIdentifier(t, stmt.Variable, "x")
+	testInfixExpression(t, stmt.Iterable, 1, "..", 10)
+
+	if len(stmt.Body.Statements) != 2 {
+		t.Fatalf("body has not 2 statements. got=%d", len(stmt.Body.Statements))
+	}
+}
+
+func TestThrowStatement(t *testing.T) {
+	program := parseProgram(t, `fyak "oops";`)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ThrowStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ThrowStatement. got=%T", program.Statements[0])
+	}
+
+	literal, ok := stmt.Value.(*ast.StringLiteral)
+	if !ok || literal.Value != "oops" {
+		t.Errorf("stmt.Value is not the string \"oops\". got=%T(%s)", stmt.Value, stmt.Value)
+	}
+}
+
+func TestTryStatement(t *testing.T) {
+	tests := []struct {
+		input      string
+		hasCatch   bool
+		hasFinally bool
+		expected   string
+	}{
+		{"prayas { x } samata (e) { e }", true, false, "prayas { x } samata (e) { e }"},
+		{"prayas { x } antama { y }", false, true, "prayas { x } antama { y }"},
+		{"prayas { x } samata (e) { e } antama { y }", true, true, "prayas { x } samata (e) { e } antama { y }"},
+		{"प्रयास { x } समात (e) { e } अन्तमा { y }", true, true, "प्रयास { x } samata (e) { e } antama { y }"},
+	}
+
+	for _, tt := range tests {
+		program := parseProgram(t, tt.input)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.TryStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.TryStatement. got=%T", program.Statements[0])
+		}
+
+		if (stmt.Catch != nil) != tt.hasCatch || (stmt.Finally != nil) != tt.hasFinally {
+			t.Errorf("wrong clauses for %q. catch=%t, finally=%t", tt.input, stmt.Catch != nil, stmt.Finally != nil)
+		}
+		if tt.hasCatch {
+			testIdentifier(t, stmt.CatchParam, "e")
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("wrong String(). expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestMalformedTryStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"prayas { x }; y", "1:13: expected next token to be samata or antama, got ; instead"},
+		{"prayas { x } samata { e }", "1:21: expected next token to be (, got { instead"},
+		{"prayas { x } samata (1) { e }", "1:22: expected next token to be IDENTIFIER, got INT instead"},
+		{"prayas x", "1:8: expected next token to be {, got IDENTIFIER instead"},
+		// the prayas block's own } must not be taken for the end of an enclosing one
+		{"prayas { x } manau y = 1;", "1:14: expected next token to be samata or antama, got LET instead"},
+		{"manau f = karya() { prayas { 1 } }; f()", "1:34: expected next token to be samata or antama, got } instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Errorf("wrong number of parser errors for %q. expected=1, got=%d: %v", tt.input, len(errors), errors)
+			continue
+		}
+
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0].Error())
+		}
+	}
+
+	// unfinished at the end of the input, so the REPL can read on
+	p := New(lexer.New("prayas { x }"))
+	p.ParseProgram()
+	if !p.Incomplete() {
+		t.Errorf("prayas without samata or antama at the end is not incomplete")
+	}
+}
+
+func TestBreakOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"roka;", "1:1: roka outside of a loop"},
+		{"yadi (x) { jari; }", "1:12: jari outside of a loop"},
+		{"jabasamma (x) { manau f = karya() { roka; }; }", "1:37: roka outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Errorf("expected 1 parser error for %q, got %v", tt.input, errors)
+			continue
+		}
+
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0].Error())
+		}
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `karya(x, y) { x + y; }`
+
+	program := parseProgram(t, input)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function literal parameters wrong. want 2, got=%d", len(function.Parameters))
+	}
+
+	testLiteralExpression(t, function.Parameters[0], "x")
+	testLiteralExpression(t, function.Parameters[1], "y")
+
+	if len(function.Body.Statements) != 1 {
+		t.Fatalf("function.Body.Statements has not 1 statement. got=%d", len(function.Body.Statements))
+	}
+
+	bodyStmt, ok := function.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("function body stmt is not ast.ExpressionStatement. got=%T", function.Body.Statements[0])
+	}
+
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
+func TestFunctionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{input: "karya() {};", expectedParams: []string{}},
+		{input: "karya(x) {};", expectedParams: []string{"x"}},
+		{input: "karya(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
+	}
+
+	for _, tt := range tests {
+		program := parseProgram(t, tt.input)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Errorf("length parameters wrong. want %d, got=%d", len(tt.expectedParams), len(function.Parameters))
+		}
+
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, function.Parameters[i], ident)
+		}
+	}
+}
+
+func TestCallExpressionParsing(t *testing.T) {
+	input := "add(1, 2 * 3, 4 + 5);"
+
+	program := parseProgram(t, input)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Function, "add") {
+		return
+	}
+
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+
+	testLiteralExpression(t, exp.Arguments[0], 1)
+	testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
+	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
+}
+
+func TestParserErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"manau = 5;", "test.goru:1:7: expected next token to be IDENTIFIER, got = instead"},
+		{"manau x 5;", "test.goru:1:9: expected next token to be =, got INT instead"},
+		{"1 +\n  ;", "test.goru:2:3: no prefix parse function for ;"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.NewFile("test.goru", tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q, got none", tt.input)
+			continue
+		}
+
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0].Error())
+		}
+	}
+}
+
+func TestIncompleteInput(t *testing.T) {
+	tests := []struct {
+		input      string
+		incomplete bool
+	}{
+		{"yadi (x < 1) {", true},
+		{"manau f = karya(a, b) {\n firta a", true},
+		{"add(1,", true},
+		{"[1, 2", true},
+		{`{"a": 1`, true},
+		{"5 +", true},
+		{"manau x =", true},
+		{"{ x", true},
+		{`manau s = "abc`, true},
+		{"manau s = \"line one\nline two", true},
+		{`chhap("a \"quoted\"`, true},
+		{`manau s = "bad \q escape"`, false},
+		{"manau = 5; yadi (x) {", false},
+		{"5 + + 2", false},
+		{"add(1 2)", false},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", tt.input)
+			continue
+		}
+
+		if p.Incomplete() != tt.incomplete {
+			t.Errorf("Incomplete() wrong for %q. expected=%t, got=%t (errors: %v)",
+				tt.input, tt.incomplete, p.Incomplete(), p.Errors())
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			"manau = 5; manau x = 1 +; manau y = 2;",
+			[]string{
+				"1:7: expected next token to be IDENTIFIER, got = instead",
+				"1:25: no prefix parse function for ;",
+			},
+		},
+		{
+			// the whole block after the bad condition is skipped
+			"yadi (x > 1 { chhap(x) (; } manau ok = ;",
+			[]string{
+				"1:13: expected next token to be ), got { instead",
+				"1:40: no prefix parse function for ;",
+			},
+		},
+		{
+			// the function body recovers and still sees its closing brace
+			"manau f = karya() { manau x = }; manau y = ;",
+			[]string{
+				"1:31: no prefix parse function for }",
+				"1:44: no prefix parse function for ;",
+			},
+		},
+		{
+			"karya() { manau = 1; 2 +; 3 }",
+			[]string{
+				"1:17: expected next token to be IDENTIFIER, got = instead",
+				"1:25: no prefix parse function for ;",
+			},
+		},
+		{
+			// a statement keyword ends the skipping even without a ';'
+			"manau x = [1, 2 manau y = 3 + manau z = ;",
+			[]string{
+				"1:17: expected next token to be ], got LET instead",
+				"1:31: no prefix parse function for LET",
+				"1:41: no prefix parse function for ;",
+			},
+		},
+		{
+			"}; roka; roka;",
+			[]string{
+				"1:1: no prefix parse function for }",
+				"1:4: roka outside of a loop",
+				"1:10: roka outside of a loop",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		var got []string
+		for _, err := range p.Errors() {
+			got = append(got, err.Error())
+		}
+
+		if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("wrong errors for %q.\nexpected:\n%s\ngot:\n%s",
+				tt.input, strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestStructuredErrors(t *testing.T) {
+	p := New(lexer.NewFile("script.goru", "manau x 5;\nmanau y = );"))
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 2 {
+		t.Fatalf("expected 2 errors, got=%d: %v", len(errors), errors)
+	}
+
+	first := errors[0]
+	if first.Pos.String() != "script.goru:1:9" || first.Expected != token.ASSIGN ||
+		first.Got.Type != token.INT || first.Got.Literal != "5" {
+		t.Errorf("first error wrong. got=%+v", first)
+	}
+	if first.Message != "expected next token to be =, got INT instead" {
+		t.Errorf("first message wrong. got=%q", first.Message)
+	}
+
+	second := errors[1]
+	if second.Pos.String() != "script.goru:2:11" || second.Expected != "" || second.Got.Type != token.RIGHTPARENTHESIS {
+		t.Errorf("second error wrong. got=%+v", second)
+	}
+	if second.Error() != "script.goru:2:11: no prefix parse function for )" {
+		t.Errorf("second Error() wrong. got=%q", second.Error())
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	input := `// the answer
+manau x = 42; // not a doc comment
+manau y = 1;
+
+/* adds */
+// two numbers
+manau add = karya(a, b) {
+	// inside a block
+	a + b
+};`
+
+	l := lexer.New(input)
+	l.KeepComments(true)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Comments) != 4 {
+		t.Fatalf("program.Comments wrong length. expected=4, got=%d", len(program.Comments))
+	}
+
+	tests := []struct {
+		statement ast.Statement
+		expected  string
+	}{
+		{program.Statements[0], "the answer"},
+		{program.Statements[1], ""},
+		{program.Statements[2], "adds\ntwo numbers"},
+	}
+
+	for i, tt := range tests {
+		doc := program.Doc(tt.statement)
+
+		text := ""
+		if doc != nil {
+			text = doc.Text()
+		}
+
+		if text != tt.expected {
+			t.Errorf("tests[%d] - doc wrong. expected=%q, got=%q", i, tt.expected, text)
+		}
+	}
+
+	function := program.Statements[2].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	body := function.Body.Statements[0]
+	if doc := program.Doc(body); doc == nil || doc.Text() != "inside a block" {
+		t.Errorf("block statement doc wrong. got=%v", doc)
+	}
+}
+
+func TestCommentsAreSkippedByDefault(t *testing.T) {
+	program := parseProgram(t, "manau x = /* one */ 1; // done")
+
+	if len(program.Comments) != 0 {
+		t.Errorf("expected no comments, got=%d", len(program.Comments))
+	}
+
+	if program.String() != "manau x = 1;" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+}
+
+func TestUnterminatedComment(t *testing.T) {
+	p := New(lexer.New("manau x = 1;\n/* still open"))
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 1 || errors[0].Error() != "2:1: unterminated comment" {
+		t.Fatalf("wrong errors. got=%q", errors)
+	}
+
+	if !p.Incomplete() {
+		t.Errorf("expected the input to be incomplete")
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	program := parseProgram(t, "manau x = 1;\nadd(x,\n  2 * 3)")
+
+	call := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+
+	if pos := call.Pos(); pos.Line != 2 || pos.Column != 1 {
+		t.Errorf("call.Pos() wrong. got=%s", pos)
+	}
+
+	if end := call.End(); end.Line != 3 || end.Column != 9 {
+		t.Errorf("call.End() wrong. got=%s", end)
+	}
+
+	if pos := call.Arguments[1].Pos(); pos.Line != 3 || pos.Column != 3 {
+		t.Errorf("argument Pos() wrong. got=%s", pos)
+	}
+}
+
 func testIntegerLiteral(t *testing.T, il ast.Expression, value int64) bool {
 	integ, ok := il.(*ast.IntegerLiteral)
 	if !ok {
@@ -295,7 +1255,7 @@ func checkParserErrors(t *testing.T, p *Parser) {
 
 	t.Errorf("parser has %d errors", len(errors))
 	for _, msg := range errors {
-		t.Errorf("parser error: %q", msg)
+		t.Errorf("parser error: %q", msg.Error())
 	}
 	t.FailNow()
 }
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/peterh/liner"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/ast"
+	"github.com/guruorgoru/goru-verbal-interpreter/diag"
+	"github.com/guruorgoru/goru-verbal-interpreter/eval"
+	"github.com/guruorgoru/goru-verbal-interpreter/lexer"
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+	"github.com/guruorgoru/goru-verbal-interpreter/parser"
+	"github.com/guruorgoru/goru-verbal-interpreter/token"
+)
+
+const (
+	EXIT                = "exit"
+	HELP                = "help"
+	PROMPT              = ">> "
+	CONTINUATION_PROMPT = ".. "
+	HISTORY_FILE        = ".goru_history"
+)
+
+// repl holds the state of one interactive session.
+type repl struct {
+	line    *liner.State
+	env     *object.Environment
+	session []string          // inputs evaluated without errors, for :save
+	sources map[string]string // every input and loaded file by file name, for diagnostics
+	inputs  int               // inputs typed so far, which name them <repl:n>
+}
+
+func startREPL() {
+	r := &repl{line: liner.NewLiner(), env: newEnvironment(), sources: map[string]string{}}
+	defer r.line.Close()
+
+	r.line.SetCtrlCAborts(true)
+	r.line.SetWordCompleter(r.complete)
+
+	historyPath := loadHistory(r.line)
+	defer saveHistory(r.line, historyPath)
+
+	var pending []string
+
+	for {
+		prompt := PROMPT
+		if len(pending) > 0 {
+			prompt = CONTINUATION_PROMPT
+		}
+
+		input, err := r.line.Prompt(prompt)
+		if errors.Is(err, liner.ErrPromptAborted) {
+			// Ctrl-C drops the line, and anything pending, without exiting
+			pending = nil
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			fmt.Println()
+			return
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		if strings.TrimSpace(input) != "" {
+			r.line.AppendHistory(input)
+		}
+
+		if len(pending) == 0 {
+			trimmed := strings.TrimSpace(input)
+			switch {
+			case trimmed == EXIT:
+				fmt.Println("Exiting interpreter. Goodbye!")
+				return
+			case trimmed == HELP:
+				printHelp()
+				continue
+			case strings.HasPrefix(trimmed, ":"):
+				r.runCommand(trimmed)
+				continue
+			}
+		}
+
+		// an empty line while continuing forces evaluation of what we have
+		force := len(pending) > 0 && strings.TrimSpace(input) == ""
+		pending = append(pending, input)
+
+		source := strings.Join(pending, "\n")
+		program, ok := r.parseInput("", source, force)
+		if !ok {
+			continue
+		}
+		pending = nil
+
+		if program == nil {
+			continue
+		}
+
+		r.evalAndPrint(program, source)
+	}
+}
+
+func printHelp() {
+	fmt.Println("Available commands:")
+	fmt.Printf("  %-22s - %s\n", HELP, "Show this help message")
+	fmt.Printf("  %-22s - %s\n", EXIT, "Exit the interpreter")
+	for _, cmd := range commands {
+		fmt.Printf("  %-22s - %s\n", cmd.usage, cmd.help)
+	}
+	fmt.Println("Unfinished input continues on the next line, an empty line ends it.")
+	fmt.Println("Tab completes names, Ctrl-R searches history, Ctrl-C cancels the line")
+	fmt.Println("and Ctrl-D exits.")
+}
+
+// evalAndPrint evaluates program in the session environment and prints the
+// result, remembering source for :save when it ran without error.
+func (r *repl) evalAndPrint(program *ast.Program, source string) object.Object {
+	evaluated := eval.Eval(program, r.env)
+	if errObj, failed := evaluated.(*object.Error); failed {
+		// the error may be in a function defined by an earlier input
+		printDiagnostic(r.sources, diag.FromRuntimeError(errObj, scopeNames(r.env)))
+		return evaluated
+	}
+
+	if evaluated != nil {
+		fmt.Println(evaluated.Inspect())
+	}
+	r.session = append(r.session, source)
+
+	return evaluated
+}
+
+// parseInput parses the input collected so far. It returns ok=false when
+// the input is unfinished and more lines should be read, unless force is
+// set. A nil program with ok=true means the errors were already reported.
+// Input without a file name is named after its place in the session, and
+// once complete it is kept so later errors can point into it.
+func (r *repl) parseInput(filename, input string, force bool) (*ast.Program, bool) {
+	typed := filename == ""
+	if typed {
+		filename = fmt.Sprintf("<repl:%d>", r.inputs+1)
+	}
+
+	p := parser.New(lexer.NewFile(filename, input))
+	program := p.ParseProgram()
+
+	if p.Incomplete() && !force {
+		return nil, false
+	}
+
+	r.sources[filename] = input
+	if typed {
+		r.inputs++
+	}
+
+	if len(p.Errors()) == 0 {
+		return program, true
+	}
+
+	for _, err := range p.Errors() {
+		printDiagnostic(r.sources, diag.FromParserError(err))
+	}
+	return nil, true
+}
+
+// loadHistory reads ~/.goru_history into the line editor and returns its
+// path, or "" when there is no home directory to keep it in.
+func loadHistory(line *liner.State) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(home, HISTORY_FILE)
+
+	if f, err := os.Open(path); err == nil {
+		line.ReadHistory(f)
+		f.Close()
+	}
+
+	return path
+}
+
+func saveHistory(line *liner.State, path string) {
+	if path == "" {
+		return
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "could not save history:", err)
+		return
+	}
+	defer f.Close()
+
+	line.WriteHistory(f)
+}
+
+// complete is the line editor's word completer for the session.
+func (r *repl) complete(input string, pos int) (string, []string, string) {
+	return completeWord(input, pos, r.env)
+}
+
+// completeWord offers keywords, builtins and names bound in env that start
+// with the word before rune offset pos in input, or command names after a
+// ':'. It returns the input around the word along with the candidates.
+func completeWord(input string, pos int, env *object.Environment) (string, []string, string) {
+	runes := []rune(input)
+	start := pos
+	for start > 0 && isWordRune(runes[start-1]) {
+		start--
+	}
+
+	head, word, tail := string(runes[:start]), string(runes[start:pos]), string(runes[pos:])
+
+	candidates := [][]string{token.Keywords(), eval.BuiltinNames(), env.Names()}
+	if strings.TrimSpace(head) == ":" {
+		candidates = [][]string{commandNames()}
+	} else if word == "" {
+		return head, nil, tail
+	}
+
+	seen := map[string]bool{}
+	var completions []string
+	for _, names := range candidates {
+		for _, name := range names {
+			if strings.HasPrefix(name, word) && !seen[name] {
+				seen[name] = true
+				completions = append(completions, name)
+			}
+		}
+	}
+
+	return head, completions, tail
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+)
+
+func TestCompleteWord(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Set("नाम", &object.String{Value: "गुरु"})
+	env.Set("naamHaru", &object.Array{})
+
+	tests := []struct {
+		input       string
+		head        string
+		completions []string
+		tail        string
+	}{
+		// names bound in the session, next to keywords and builtins
+		{"naa", "", []string{"naamHaru"}, ""},
+		{"chhap(naa", "chhap(", []string{"naamHaru"}, ""},
+		{"le", "", []string{"len"}, ""},
+		{"manau x = ka", "manau x = ", []string{"karya"}, ""},
+		// a vowel sign is a combining mark and belongs to the word
+		{"chhap(ना", "chhap(", []string{"नाम"}, ""},
+		{"मा", "", []string{"मा", "मानौ"}, ""},
+		{"x + नाम", "x + ", []string{"नाम"}, ""},
+		// command names after a ':'
+		{":lo", ":", []string{"load"}, ""},
+		{":", ":", commandNames(), ""},
+		{": t", ": ", []string{"tokens", "type", "time"}, ""},
+		// nothing typed yet offers nothing
+		{"manau x = ", "manau x = ", nil, ""},
+		{"qqq", "", nil, ""},
+	}
+
+	for _, tt := range tests {
+		head, completions, tail := completeWord(tt.input, utf8.RuneCountInString(tt.input), env)
+		if head != tt.head || tail != tt.tail {
+			t.Errorf("wrong split of %q. expected=(%q, %q), got=(%q, %q)", tt.input, tt.head, tt.tail, head, tail)
+		}
+		if !reflect.DeepEqual(completions, tt.completions) {
+			t.Errorf("wrong completions for %q. expected=%q, got=%q", tt.input, tt.completions, completions)
+		}
+	}
+}
+
+func TestCompleteWordInTheMiddle(t *testing.T) {
+	env := object.NewEnvironment()
+
+	// the cursor sits after "मानौ x = le", counted in runes
+	input := "मानौ x = le(y)"
+	head, completions, tail := completeWord(input, utf8.RuneCountInString("मानौ x = le"), env)
+
+	if head != "मानौ x = " || tail != "(y)" {
+		t.Errorf("wrong split. got=(%q, %q)", head, tail)
+	}
+	if !reflect.DeepEqual(completions, []string{"len"}) {
+		t.Errorf("wrong completions. got=%q", completions)
+	}
+}
+
+func TestErrorInAnEarlierInput(t *testing.T) {
+	r := &repl{env: object.NewEnvironment(), sources: map[string]string{}}
+
+	for _, input := range []string{"manau f = karya(x) {\n\tx + satya\n};", "1 + 1"} {
+		program, ok := r.parseInput("", input, false)
+		if !ok || program == nil {
+			t.Fatalf("could not parse %q", input)
+		}
+		captureStdout(t, func() { r.evalAndPrint(program, input) })
+	}
+
+	program, _ := r.parseInput("", "f(1)", false)
+	out := captureStderr(t, func() { r.evalAndPrint(program, "f(1)") })
+
+	// the snippet is the line of the input the function was defined in
+	expected := "<repl:1>:2:2: error: type mismatch: INTEGER + BOOLEAN\n" +
+		"  |\n" +
+		"2 |     x + satya\n" +
+		"  |     ^^^^^^^^^\n" +
+		"  = trace, most recent call first:\n" +
+		"      at f (<repl:3>:1:1)\n"
+	if out != expected {
+		t.Errorf("wrong diagnostic.\nexpected:\n%s\ngot:\n%s", expected, out)
+	}
+}
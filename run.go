@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/diag"
+	"github.com/guruorgoru/goru-verbal-interpreter/eval"
+	"github.com/guruorgoru/goru-verbal-interpreter/lexer"
+	"github.com/guruorgoru/goru-verbal-interpreter/object"
+	"github.com/guruorgoru/goru-verbal-interpreter/parser"
+)
+
+func runFile(filename string, args []string) int {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return runSource(filename, string(source), args, false)
+}
+
+// runSource parses and evaluates a whole program, reporting every parse
+// error or the runtime error on stderr. The result is printed only when
+// printResult is set, as for -e one-liners.
+func runSource(filename, source string, args []string, printResult bool) int {
+	p := parser.New(lexer.NewFile(filename, source))
+	program := p.ParseProgram()
+	sources := map[string]string{filename: source}
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			printDiagnostic(sources, diag.FromParserError(err))
+		}
+		return 1
+	}
+
+	env := newEnvironment()
+	env.Set("args", scriptArgs(args))
+
+	evaluated := eval.Eval(program, env)
+	if errObj, ok := evaluated.(*object.Error); ok {
+		printDiagnostic(sources, diag.FromRuntimeError(errObj, scopeNames(env)))
+		return 1
+	}
+
+	if printResult && evaluated != nil && evaluated != eval.NULL {
+		fmt.Println(evaluated.Inspect())
+	}
+
+	return 0
+}
+
+// printDiagnostic writes d to stderr, showing the line it points at from
+// sources, which are keyed by file name.
+func printDiagnostic(sources map[string]string, d *diag.Diagnostic) {
+	printer := &diag.Printer{Sources: sources, Color: diag.UseColor(os.Stderr)}
+	printer.Fprint(os.Stderr, d)
+}
+
+// scopeNames lists the names a misspelt identifier in env may have meant.
+func scopeNames(env *object.Environment) []string {
+	return append(env.Names(), eval.BuiltinNames()...)
+}
+
+func scriptArgs(args []string) *object.Array {
+	elements := make([]object.Object, len(args))
+	for i, arg := range args {
+		elements[i] = &object.String{Value: arg}
+	}
+	return &object.Array{Elements: elements}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guruorgoru/goru-verbal-interpreter/eval"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		expr   string
+		args   []string // $FILE stands for the path of script
+		script string
+		stdin  string
+		status int
+		stdout string
+		stderr string // a part of what is printed to stderr
+	}{
+		{name: "-e prints its result", expr: "1 + 2", status: 0, stdout: "3\n"},
+		{name: "-e prints nothing for null", expr: "manau x = 1;", status: 0, stdout: ""},
+		{name: "-e binds args", expr: "args", args: []string{"a", "b c"}, status: 0, stdout: "[a, b c]\n"},
+		{name: "-e runtime error", expr: "x", status: 1, stderr: "-e:1:1: error: identifier not found: x"},
+		{name: "-e parse error", expr: "manau = 1;", status: 1, stderr: "-e:1:7: error:"},
+		{
+			name:   "script binds args",
+			args:   []string{"run", "$FILE", "a", "b"},
+			script: "chhap(len(args), args[1]);\nargs",
+			status: 0,
+			// the value of a script is not printed
+			stdout: "2 b\n",
+		},
+		{name: "script without args", args: []string{"run", "$FILE"}, script: "chhap(args)", status: 0, stdout: "[]\n"},
+		{name: "script parse error", args: []string{"run", "$FILE"}, script: "manau x = ;", status: 1, stderr: ".goru:1:11: error:"},
+		{name: "script runtime error", args: []string{"run", "$FILE"}, script: "chhap(1);\n1 + satya", status: 1, stdout: "1\n", stderr: ".goru:2:1: error: type mismatch"},
+		{name: "missing script", args: []string{"run", "nope.goru"}, status: 1, stderr: "nope.goru"},
+		{name: "run without a file", args: []string{"run"}, status: 2},
+		{name: "unknown argument", args: []string{"script.goru"}, status: 2},
+		{name: "program from stdin", stdin: "manau x = 21;\nchhap(x * 2);\nx", status: 0, stdout: "42\n"},
+		{name: "stdin runtime error", stdin: "chhap(y)", status: 1, stderr: "<stdin>:1:7: error: identifier not found: y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			script := filepath.Join(dir, "script.goru")
+			if err := os.WriteFile(script, []byte(tt.script), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			args := make([]string, len(tt.args))
+			for i, arg := range tt.args {
+				args[i] = strings.ReplaceAll(arg, "$FILE", script)
+			}
+
+			// stdin is never a terminal here, so run does not start the REPL
+			stdin := filepath.Join(dir, "stdin")
+			if err := os.WriteFile(stdin, []byte(tt.stdin), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(stdin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			saved := os.Stdin
+			os.Stdin = f
+			defer func() { os.Stdin = saved }()
+
+			var status int
+			var stdout string
+			stderr := captureStderr(t, func() {
+				stdout = captureStdout(t, func() {
+					// chhap writes to eval.Stdout, bound to the real stdout
+					saved := eval.Stdout
+					eval.Stdout = os.Stdout
+					defer func() { eval.Stdout = saved }()
+
+					status = run(tt.expr, args)
+				})
+			})
+
+			if status != tt.status {
+				t.Errorf("wrong exit status. expected=%d, got=%d (stderr %q)", tt.status, status, stderr)
+			}
+			if stdout != tt.stdout {
+				t.Errorf("wrong stdout. expected=%q, got=%q", tt.stdout, stdout)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("stderr does not contain %q. got=%q", tt.stderr, stderr)
+			}
+			if tt.status == 0 && stderr != "" {
+				t.Errorf("unexpected stderr: %q", stderr)
+			}
+		})
+	}
+}
+
+func TestScriptArgs(t *testing.T) {
+	if got := scriptArgs(nil).Inspect(); got != "[]" {
+		t.Errorf("wrong args for no arguments. got=%s", got)
+	}
+	if got := scriptArgs([]string{"-x", "नाम"}).Inspect(); got != "[-x, नाम]" {
+		t.Errorf("wrong args. got=%s", got)
+	}
+}
@@ -1,18 +1,58 @@
 package token
 
+import (
+	"fmt"
+	"sort"
+)
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // where the token starts
+	End     Position // just past the last character of the token
+}
+
+// Position is a location in the source, lines and columns start at 1.
+
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+// String formats the position as file:line:col, leaving out the file
+// when it is unknown.
+func (pos Position) String() string {
+	if !pos.IsValid() {
+		if pos.Filename != "" {
+			return pos.Filename
+		}
+		return "-"
+	}
+
+	if pos.Filename != "" {
+		return fmt.Sprintf("%s:%d:%d", pos.Filename, pos.Line, pos.Column)
+	}
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
 }
 
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
 
+	COMMENT = "COMMENT"
+
 	IDENTIFIER = "IDENTIFIER"
 	INT        = "INT"
+	FLOAT      = "FLOAT"
+	STRING     = "STRING"
 
 	PLUS     = "+"
 	ASSIGN   = "="
@@ -20,18 +60,42 @@ const (
 	MINUS    = "-"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
+	POWER    = "**"
+	TILDE    = "~"
+
+	PLUSASSIGN     = "+="
+	MINUSASSIGN    = "-="
+	ASTERISKASSIGN = "*="
+	SLASHASSIGN    = "/="
+	PERCENTASSIGN  = "%="
+
+	COMMA        = ","
+	SEMICOLON    = ";"
+	COLON        = ":"
+	DOTDOT       = ".."
+	DOTDOTEQ     = "..="
+	LESSERTHAN   = "<"
+	GREATERTHAN  = ">"
+	LESSEQUAL    = "<="
+	GREATEREQUAL = ">="
+	EQUALS       = "=="
+	NOTEQUALS    = "!="
+	AND          = "&&"
+	OR           = "||"
 
-	COMMA       = ","
-	SEMICOLON   = ";"
-	LESSERTHAN  = "<"
-	GREATERTHAN = ">"
-	EQUALS      = "=="
-	NOTEQUALS   = "!="
+	BITAND     = "&"
+	BITOR      = "|"
+	BITXOR     = "^"
+	SHIFTLEFT  = "<<"
+	SHIFTRIGHT = ">>"
 
 	LEFTPARENTHESIS  = "("
 	RIGHTPARENTHESIS = ")"
 	LEFTBRACES       = "{"
 	RIGHTBRACES      = "}"
+	LEFTBRACKET      = "["
+	RIGHTBRACKET     = "]"
 
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
@@ -40,6 +104,15 @@ const (
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
 	RETURN   = "RETURN"
+	WHILE    = "WHILE"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	FOR      = "FOR"
+	IN       = "IN"
+	THROW    = "THROW"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
 )
 
 // Keywords contains the keywords usable in that langauge
@@ -52,6 +125,48 @@ var keywords = map[string]TokenType{
 	"satya": TRUE,
 	"jhuth": FALSE,
 	"firta": RETURN,
+
+	"jabasamma": WHILE,
+	"roka":      BREAK,
+	"jari":      CONTINUE,
+	"pratyek":   FOR,
+	"ma":        IN,
+	"ra":        AND,
+	"wa":        OR,
+	"fyak":      THROW,
+	"prayas":    TRY,
+	"samata":    CATCH,
+	"antama":    FINALLY,
+
+	// the same keywords in Devanagari script
+	"कार्य":    FUNCTION,
+	"मानौ":     LET,
+	"यदि":      IF,
+	"नत्र":     ELSE,
+	"सत्य":     TRUE,
+	"झूठ":      FALSE,
+	"फिर्ता":   RETURN,
+	"जबसम्म":   WHILE,
+	"रोक":      BREAK,
+	"जारी":     CONTINUE,
+	"प्रत्येक": FOR,
+	"मा":       IN,
+	"र":        AND,
+	"वा":       OR,
+	"फ्याँक":   THROW,
+	"प्रयास":   TRY,
+	"समात":     CATCH,
+	"अन्तमा":   FINALLY,
+}
+
+// Keywords returns the spelling of every keyword, sorted.
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
 }
 
 func LookForIdentifier(identifier string) TokenType {